@@ -1,5 +1,11 @@
 package algorithms
 
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"fmt"
+)
+
 // Implementation from FIPS 197
 // AES block size is 16 bytes
 const blockSize = 16
@@ -24,38 +30,76 @@ var sBox = [256]byte{
 	0x8C, 0xA1, 0x89, 0x0D, 0xBF, 0xE6, 0x42, 0x68, 0x41, 0x99, 0x2D, 0x0F, 0xB0, 0x54, 0xBB, 0x16,
 }
 
+// Inverse S-box for InvSubBytes step
+var invSBox = [256]byte{
+	0x52, 0x09, 0x6A, 0xD5, 0x30, 0x36, 0xA5, 0x38, 0xBF, 0x40, 0xA3, 0x9E, 0x81, 0xF3, 0xD7, 0xFB,
+	0x7C, 0xE3, 0x39, 0x82, 0x9B, 0x2F, 0xFF, 0x87, 0x34, 0x8E, 0x43, 0x44, 0xC4, 0xDE, 0xE9, 0xCB,
+	0x54, 0x7B, 0x94, 0x32, 0xA6, 0xC2, 0x23, 0x3D, 0xEE, 0x4C, 0x95, 0x0B, 0x42, 0xFA, 0xC3, 0x4E,
+	0x08, 0x2E, 0xA1, 0x66, 0x28, 0xD9, 0x24, 0xB2, 0x76, 0x5B, 0xA2, 0x49, 0x6D, 0x8B, 0xD1, 0x25,
+	0x72, 0xF8, 0xF6, 0x64, 0x86, 0x68, 0x98, 0x16, 0xD4, 0xA4, 0x5C, 0xCC, 0x5D, 0x65, 0xB6, 0x92,
+	0x6C, 0x70, 0x48, 0x50, 0xFD, 0xED, 0xB9, 0xDA, 0x5E, 0x15, 0x46, 0x57, 0xA7, 0x8D, 0x9D, 0x84,
+	0x90, 0xD8, 0xAB, 0x00, 0x8C, 0xBC, 0xD3, 0x0A, 0xF7, 0xE4, 0x58, 0x05, 0xB8, 0xB3, 0x45, 0x06,
+	0xD0, 0x2C, 0x1E, 0x8F, 0xCA, 0x3F, 0x0F, 0x02, 0xC1, 0xAF, 0xBD, 0x03, 0x01, 0x13, 0x8A, 0x6B,
+	0x3A, 0x91, 0x11, 0x41, 0x4F, 0x67, 0xDC, 0xEA, 0x97, 0xF2, 0xCF, 0xCE, 0xF0, 0xB4, 0xE6, 0x73,
+	0x96, 0xAC, 0x74, 0x22, 0xE7, 0xAD, 0x35, 0x85, 0xE2, 0xF9, 0x37, 0xE8, 0x1C, 0x75, 0xDF, 0x6E,
+	0x47, 0xF1, 0x1A, 0x71, 0x1D, 0x29, 0xC5, 0x89, 0x6F, 0xB7, 0x62, 0x0E, 0xAA, 0x18, 0xBE, 0x1B,
+	0xFC, 0x56, 0x3E, 0x4B, 0xC6, 0xD2, 0x79, 0x20, 0x9A, 0xDB, 0xC0, 0xFE, 0x78, 0xCD, 0x5A, 0xF4,
+	0x1F, 0xDD, 0xA8, 0x33, 0x88, 0x07, 0xC7, 0x31, 0xB1, 0x12, 0x10, 0x59, 0x27, 0x80, 0xEC, 0x5F,
+	0x60, 0x51, 0x7F, 0xA9, 0x19, 0xB5, 0x4A, 0x0D, 0x2D, 0xE5, 0x7A, 0x9F, 0x93, 0xC9, 0x9C, 0xEF,
+	0xA0, 0xE0, 0x3B, 0x4D, 0xAE, 0x2A, 0xF5, 0xB0, 0xC8, 0xEB, 0xBB, 0x3C, 0x83, 0x53, 0x99, 0x61,
+	0x17, 0x2B, 0x04, 0x7E, 0xBA, 0x77, 0xD6, 0x26, 0xE1, 0x69, 0x14, 0x63, 0x55, 0x21, 0x0C, 0x7D,
+}
+
 // Rcon for the key expansion step
 var rcon = [10]byte{0x01, 0x02, 0x04, 0x08, 0x10, 0x20, 0x40, 0x80, 0x1B, 0x36}
 
-// Key expansion function
+// numRounds - Nr for a key of Nk 32-bit words: 10, 12 or 14 for AES-128, AES-192 and AES-256
+func numRounds(key []byte) int {
+	switch len(key) {
+	case 16, 24, 32:
+		return len(key)/4 + 6
+	}
+	panic(fmt.Sprintf("algorithms: invalid AES key size %d", len(key)))
+}
+
+// Key expansion function (FIPS 197, Section 5.2)
+// Returns Nr+1 round keys of 16 bytes each
 func keyExpansion(key []byte) [][]byte {
-	numRounds := 10
-	roundKeys := make([][]byte, numRounds+1)
-	roundKeys[0] = make([]byte, len(key))
-	copy(roundKeys[0], key)
+	nk := len(key) / 4
+	numRounds := numRounds(key)
+	numWords := 4 * (numRounds + 1)
 
-	for i := 1; i <= numRounds; i++ {
-		prev := roundKeys[i-1]
-		temp := make([]byte, 4)
+	// w holds the key schedule as a flat byte slice, 4 bytes per word
+	w := make([]byte, 4*numWords)
+	copy(w, key)
 
-		// Rotate and substitute (the first word)
-		temp[0], temp[1], temp[2], temp[3] = prev[13], prev[14], prev[15], prev[12]
-		for j := 0; j < 4; j++ {
-			temp[j] = sBox[temp[j]]
+	temp := make([]byte, 4)
+	for i := nk; i < numWords; i++ {
+		copy(temp, w[4*(i-1):4*i])
+
+		if i%nk == 0 {
+			// RotWord, SubWord and Rcon
+			temp[0], temp[1], temp[2], temp[3] = temp[1], temp[2], temp[3], temp[0]
+			for j := 0; j < 4; j++ {
+				temp[j] = sBox[temp[j]]
+			}
+			temp[0] ^= rcon[i/nk-1]
+		} else if nk > 6 && i%nk == 4 {
+			// AES-256 applies SubWord to every fourth word as well
+			for j := 0; j < 4; j++ {
+				temp[j] = sBox[temp[j]]
+			}
 		}
-		temp[0] ^= rcon[i-1]
 
-		// Generate new round key
-		roundKey := make([]byte, len(key))
 		for j := 0; j < 4; j++ {
-			roundKey[j] = prev[j] ^ temp[j]
-		}
-		for j := 4; j < len(key); j++ {
-			roundKey[j] = prev[j] ^ roundKey[j-4]
+			w[4*i+j] = w[4*(i-nk)+j] ^ temp[j]
 		}
-		roundKeys[i] = roundKey
 	}
 
+	roundKeys := make([][]byte, numRounds+1)
+	for i := range roundKeys {
+		roundKeys[i] = w[i*blockSize : (i+1)*blockSize]
+	}
 	return roundKeys
 }
 
@@ -84,24 +128,25 @@ func shiftRows(state []byte) {
 	state[3], state[7], state[11], state[15] = temp[15], temp[3], temp[7], temp[11]
 }
 
-// MixColumns step
-func mixColumns(state []byte) {
-	mul := func(a, b byte) byte {
-		var p byte
-		for b > 0 {
-			if b&1 == 1 {
-				p ^= a
-			}
-			if a&0x80 != 0 {
-				a = (a << 1) ^ 0x1B
-			} else {
-				a <<= 1
-			}
-			b >>= 1
+// Multiplication in GF(2^8) modulo x^8 + x^4 + x^3 + x + 1
+func mul(a, b byte) byte {
+	var p byte
+	for b > 0 {
+		if b&1 == 1 {
+			p ^= a
+		}
+		if a&0x80 != 0 {
+			a = (a << 1) ^ 0x1B
+		} else {
+			a <<= 1
 		}
-		return p
+		b >>= 1
 	}
+	return p
+}
 
+// MixColumns step
+func mixColumns(state []byte) {
 	for i := 0; i < 4; i++ {
 		col := state[i*4 : (i+1)*4]
 		temp := make([]byte, 4)
@@ -114,18 +159,47 @@ func mixColumns(state []byte) {
 	}
 }
 
-// AES encryption
-func AesEncrypt(input, key []byte) []byte {
-	state := make([]byte, blockSize)
-	copy(state, input)
+// InvSubBytes step
+func invSubBytes(state []byte) {
+	for i := 0; i < blockSize; i++ {
+		state[i] = invSBox[state[i]]
+	}
+}
+
+// InvShiftRows step
+func invShiftRows(state []byte) {
+	temp := make([]byte, blockSize)
+	copy(temp, state)
+
+	// Perform shifts in the opposite direction
+	state[1], state[5], state[9], state[13] = temp[13], temp[1], temp[5], temp[9]
+	state[2], state[6], state[10], state[14] = temp[10], temp[14], temp[2], temp[6]
+	state[3], state[7], state[11], state[15] = temp[7], temp[11], temp[15], temp[3]
+}
+
+// InvMixColumns step
+func invMixColumns(state []byte) {
+	for i := 0; i < 4; i++ {
+		col := state[i*4 : (i+1)*4]
+		temp := make([]byte, 4)
+		copy(temp, col)
 
-	roundKeys := keyExpansion(key)
+		col[0] = mul(temp[0], 14) ^ mul(temp[1], 11) ^ mul(temp[2], 13) ^ mul(temp[3], 9)
+		col[1] = mul(temp[0], 9) ^ mul(temp[1], 14) ^ mul(temp[2], 11) ^ mul(temp[3], 13)
+		col[2] = mul(temp[0], 13) ^ mul(temp[1], 9) ^ mul(temp[2], 14) ^ mul(temp[3], 11)
+		col[3] = mul(temp[0], 11) ^ mul(temp[1], 13) ^ mul(temp[2], 9) ^ mul(temp[3], 14)
+	}
+}
+
+// encryptBlock - The cipher (FIPS 197 Section 5.1) on one state with an expanded key
+func encryptBlock(state []byte, roundKeys [][]byte) {
+	numRounds := len(roundKeys) - 1
 
 	// Initial round
 	addRoundKey(state, roundKeys[0])
 
 	// Main rounds
-	for round := 1; round <= 9; round++ {
+	for round := 1; round < numRounds; round++ {
 		subBytes(state)
 		shiftRows(state)
 		mixColumns(state)
@@ -135,7 +209,84 @@ func AesEncrypt(input, key []byte) []byte {
 	// Final round
 	subBytes(state)
 	shiftRows(state)
-	addRoundKey(state, roundKeys[10])
+	addRoundKey(state, roundKeys[numRounds])
+}
+
+// decryptBlock - The inverse cipher (FIPS 197 Section 5.3) on one state with an expanded key
+func decryptBlock(state []byte, roundKeys [][]byte) {
+	numRounds := len(roundKeys) - 1
+
+	// Initial round
+	addRoundKey(state, roundKeys[numRounds])
+
+	// Main rounds
+	for round := numRounds - 1; round >= 1; round-- {
+		invShiftRows(state)
+		invSubBytes(state)
+		addRoundKey(state, roundKeys[round])
+		invMixColumns(state)
+	}
+
+	// Final round
+	invShiftRows(state)
+	invSubBytes(state)
+	addRoundKey(state, roundKeys[0])
+}
 
+// AES encryption with a 16, 24 or 32 byte key (AES-128, AES-192, AES-256)
+// Panics on any other key size
+func AesEncrypt(input, key []byte) []byte {
+	state := make([]byte, blockSize)
+	copy(state, input)
+	encryptBlock(state, keyExpansion(key))
 	return state
 }
+
+// AES decryption (the inverse cipher) with a 16, 24 or 32 byte key
+// Panics on any other key size
+func AesDecrypt(input, key []byte) []byte {
+	state := make([]byte, blockSize)
+	copy(state, input)
+	decryptBlock(state, keyExpansion(key))
+	return state
+}
+
+// aesBlock - The FIPS 197 implementation as a cipher.Block, with the key schedule expanded once
+type aesBlock struct {
+	roundKeys [][]byte
+}
+
+// NewAES returns the in-house AES as a cipher.Block, usable wherever crypto/aes is.
+// The key must be 16, 24 or 32 bytes.
+func NewAES(key []byte) (cipher.Block, error) {
+	switch len(key) {
+	case 16, 24, 32:
+	default:
+		return nil, aes.KeySizeError(len(key))
+	}
+	return &aesBlock{roundKeys: keyExpansion(key)}, nil
+}
+
+func (b *aesBlock) BlockSize() int {
+	return blockSize
+}
+
+func (b *aesBlock) Encrypt(dst, src []byte) {
+	if len(src) < blockSize || len(dst) < blockSize {
+		panic("algorithms: input not full block")
+	}
+	state := make([]byte, blockSize)
+	copy(state, src)
+	encryptBlock(state, b.roundKeys)
+	copy(dst, state)
+}
+
+func (b *aesBlock) Decrypt(dst, src []byte) {
+	if len(src) < blockSize || len(dst) < blockSize {
+		panic("algorithms: input not full block")
+	}
+	state := make([]byte, blockSize)
+	copy(state, src)
+	decryptBlock(state, b.roundKeys)
+	copy(dst, state)
+}
@@ -0,0 +1,76 @@
+// alphabet.go
+package algorithms
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Alphabet is an ordered set of characters; the numeral of a character is its position.
+// Characters are Unicode code points, so multibyte alphabets (Cyrillic, Greek, accented Latin, ...)
+// work the same as ASCII ones. An Alphabet is immutable and safe for concurrent use.
+type Alphabet struct {
+	chars  []rune
+	lookup map[rune]uint16
+}
+
+// NewAlphabet builds an Alphabet from the characters of chars, in order.
+// Fails with ErrDuplicateCharacter if a character repeats and ErrInvalidAlphabet
+// unless there are between 2 and 2^16 characters.
+func NewAlphabet(chars string) (*Alphabet, error) {
+	runes := []rune(chars)
+	if len(runes) < minRadix || len(runes) > maxRadix {
+		return nil, fmt.Errorf("%w: got %d", ErrInvalidAlphabet, len(runes))
+	}
+
+	lookup := make(map[rune]uint16, len(runes))
+	for i, r := range runes {
+		if _, exists := lookup[r]; exists {
+			return nil, fmt.Errorf("%w: %q", ErrDuplicateCharacter, r)
+		}
+		lookup[r] = uint16(i)
+	}
+	return &Alphabet{chars: runes, lookup: lookup}, nil
+}
+
+// Radix - The number of characters in the alphabet
+func (a *Alphabet) Radix() uint64 {
+	return uint64(len(a.chars))
+}
+
+// Contains reports whether r is a character of the alphabet
+func (a *Alphabet) Contains(r rune) bool {
+	_, exists := a.lookup[r]
+	return exists
+}
+
+// Encode converts a character string to its numeral string, one numeral per character
+func (a *Alphabet) Encode(s string) ([]uint16, error) {
+	numerals := make([]uint16, 0, len(s))
+	for _, r := range s {
+		num, exists := a.lookup[r]
+		if !exists {
+			return nil, fmt.Errorf("%w: %q", ErrInvalidCharacter, r)
+		}
+		numerals = append(numerals, num)
+	}
+	return numerals, nil
+}
+
+// Decode converts a numeral string back to its character string
+func (a *Alphabet) Decode(X []uint16) (string, error) {
+	var sb strings.Builder
+	sb.Grow(len(X))
+	for i, x := range X {
+		if int(x) >= len(a.chars) {
+			return "", fmt.Errorf("%w: X[%d] = %d, radix %d", ErrNumeralOutOfRange, i, x, len(a.chars))
+		}
+		sb.WriteRune(a.chars[x])
+	}
+	return sb.String(), nil
+}
+
+// String - The characters of the alphabet, in order
+func (a *Alphabet) String() string {
+	return string(a.chars)
+}
@@ -0,0 +1,69 @@
+// alphabets.go
+package algorithms
+
+import (
+	"fmt"
+	"sort"
+)
+
+// Standard alphabets, looked up by name with LookupAlphabet
+var standardAlphabets = map[string]string{
+	"digits":           "0123456789",
+	"lowercase":        "abcdefghijklmnopqrstuvwxyz",
+	"uppercase":        "ABCDEFGHIJKLMNOPQRSTUVWXYZ",
+	"base36":           "0123456789abcdefghijklmnopqrstuvwxyz",
+	"base62":           "0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz",
+	"hex":              "0123456789abcdef",
+	"HEX":              "0123456789ABCDEF",
+	"base32":           "ABCDEFGHIJKLMNOPQRSTUVWXYZ234567",                                 // RFC 4648, Section 6
+	"base32-crockford": "0123456789ABCDEFGHJKMNPQRSTVWXYZ",                                 // Crockford, without I, L, O and U
+	"base64url":        "ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz0123456789-_", // RFC 4648, Section 5
+	"printable":        " !\"#$%&'()*+,-./0123456789:;<=>?@ABCDEFGHIJKLMNOPQRSTUVWXYZ[\\]^_`abcdefghijklmnopqrstuvwxyz{|}~",
+}
+
+// Alternative names for the standard alphabets
+var alphabetAliases = map[string]string{
+	"base10": "digits",
+	"base16": "hex",
+	"base26": "lowercase",
+}
+
+// Parsed once, Alphabet values are immutable and can be shared
+var alphabetRegistry = func() map[string]*Alphabet {
+	registry := make(map[string]*Alphabet, len(standardAlphabets))
+	for name, chars := range standardAlphabets {
+		registry[name] = mustAlphabet(chars)
+	}
+	return registry
+}()
+
+// mustAlphabet - NewAlphabet for the package's own constant alphabets, panics on error
+func mustAlphabet(chars string) *Alphabet {
+	a, err := NewAlphabet(chars)
+	if err != nil {
+		panic(fmt.Sprintf("algorithms: alphabet %q: %v", chars, err))
+	}
+	return a
+}
+
+// LookupAlphabet returns the standard alphabet with the given name (see AlphabetNames)
+func LookupAlphabet(name string) (*Alphabet, error) {
+	if alias, ok := alphabetAliases[name]; ok {
+		name = alias
+	}
+	a, ok := alphabetRegistry[name]
+	if !ok {
+		return nil, fmt.Errorf("%w: %q", ErrUnknownAlphabet, name)
+	}
+	return a, nil
+}
+
+// AlphabetNames - The names of the standard alphabets, sorted, without aliases
+func AlphabetNames() []string {
+	names := make([]string, 0, len(standardAlphabets))
+	for name := range standardAlphabets {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
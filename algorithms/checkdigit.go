@@ -0,0 +1,379 @@
+// checkdigit.go
+package algorithms
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// CheckDigit is a check-character scheme of a formatted identifier (card numbers, IBANs, ISBNs, ...)
+type CheckDigit interface {
+	// Positions returns the indices of the check characters in an identifier of n characters
+	Positions(n int) []int
+	// Compute returns the check characters of s, in the order of Positions.
+	// The characters of s at those positions are ignored.
+	Compute(s string) (string, error)
+}
+
+// FixedCheckDigit is a CheckDigit whose identifiers have characters outside the check positions
+// that CheckDigitCipher must not encrypt, such as the country code of an IBAN
+type FixedCheckDigit interface {
+	CheckDigit
+	// Fixed returns the indices of the characters to keep in an identifier of n characters
+	Fixed(n int) []int
+}
+
+// ValidateCheckDigit returns ErrCheckDigit if the check characters of s do not match the scheme
+func ValidateCheckDigit(check CheckDigit, s string) error {
+	runes := []rune(s)
+	checkChars, err := check.Compute(s)
+	if err != nil {
+		return err
+	}
+	want := []rune(checkChars)
+	for i, pos := range check.Positions(len(runes)) {
+		if pos < 0 || pos >= len(runes) {
+			return fmt.Errorf("%w: no check position %d in %q", ErrInputLength, pos, s)
+		}
+		if runes[pos] != want[i] {
+			return fmt.Errorf("%w: %q", ErrCheckDigit, s)
+		}
+	}
+	return nil
+}
+
+// payloadDigits - The decimal digits of s outside the check positions
+func payloadDigits(s string, positions []int) ([]uint16, error) {
+	runes := []rune(s)
+	for _, pos := range positions {
+		if pos < 0 || pos >= len(runes) {
+			return nil, fmt.Errorf("%w: no check position %d in %q", ErrInputLength, pos, s)
+		}
+	}
+	var digits []uint16
+next:
+	for i, r := range runes {
+		for _, pos := range positions {
+			if i == pos {
+				continue next
+			}
+		}
+		if r < '0' || r > '9' {
+			return nil, fmt.Errorf("%w: %q", ErrInvalidCharacter, r)
+		}
+		digits = append(digits, uint16(r-'0'))
+	}
+	return digits, nil
+}
+
+// lastPosition - The check character is the last one
+func lastPosition(n int) []int {
+	return []int{n - 1}
+}
+
+// Luhn - The mod 10 "double every other digit" scheme of payment cards (ISO/IEC 7812-1)
+type Luhn struct{}
+
+func (Luhn) Positions(n int) []int { return lastPosition(n) }
+
+func (Luhn) Compute(s string) (string, error) {
+	payload, err := payloadDigits(s, lastPosition(len([]rune(s))))
+	if err != nil {
+		return "", err
+	}
+	return string(rune('0' + luhnCheckDigit(payload))), nil
+}
+
+// IBAN - ISO 7064 MOD 97-10 as used by IBANs: two check digits after the country code
+type IBAN struct{}
+
+func (IBAN) Positions(int) []int { return []int{2, 3} }
+
+// Fixed - The country code
+func (IBAN) Fixed(int) []int { return []int{0, 1} }
+
+func (IBAN) Compute(s string) (string, error) {
+	if len(s) < 5 {
+		return "", fmt.Errorf("%w: IBAN %q is too short", ErrInputLength, s)
+	}
+	// BBAN || country code || "00", letters counted as 10 to 35
+	rem := 0
+	for _, r := range s[4:] + s[:2] + "00" {
+		switch {
+		case r >= '0' && r <= '9':
+			rem = (rem*10 + int(r-'0')) % 97
+		case r >= 'A' && r <= 'Z':
+			rem = (rem*100 + int(r-'A') + 10) % 97
+		default:
+			return "", fmt.Errorf("%w: %q", ErrInvalidCharacter, r)
+		}
+	}
+	return fmt.Sprintf("%02d", 98-rem), nil
+}
+
+// ISBN10 - Weighted mod 11 with 'X' for 10
+type ISBN10 struct{}
+
+func (ISBN10) Positions(int) []int { return []int{9} }
+
+func (ISBN10) Compute(s string) (string, error) {
+	if len([]rune(s)) != 10 {
+		return "", fmt.Errorf("%w: an ISBN-10 has 10 characters, got %q", ErrInputLength, s)
+	}
+	payload, err := payloadDigits(s, []int{9})
+	if err != nil {
+		return "", err
+	}
+	sum := 0
+	for i, d := range payload {
+		sum += (10 - i) * int(d)
+	}
+	return mod11Char((11 - sum%11) % 11), nil
+}
+
+// EAN - The GS1 mod 10 scheme with weights 3 and 1, used by EAN-8, EAN-13, UPC-A and GTIN-14
+type EAN struct{}
+
+func (EAN) Positions(n int) []int { return lastPosition(n) }
+
+func (EAN) Compute(s string) (string, error) {
+	payload, err := payloadDigits(s, lastPosition(len([]rune(s))))
+	if err != nil {
+		return "", err
+	}
+	sum := 0
+	for i := range payload {
+		weight := 1
+		if i%2 == 0 {
+			weight = 3
+		}
+		sum += weight * int(payload[len(payload)-1-i])
+	}
+	return string(rune('0' + (10-sum%10)%10)), nil
+}
+
+// ISBN13 - An ISBN-13 is an EAN-13 in the 978 or 979 range
+type ISBN13 struct{}
+
+func (ISBN13) Positions(int) []int { return []int{12} }
+
+// Fixed - The 978 or 979 prefix
+func (ISBN13) Fixed(int) []int { return []int{0, 1, 2} }
+
+func (ISBN13) Compute(s string) (string, error) {
+	if len([]rune(s)) != 13 {
+		return "", fmt.Errorf("%w: an ISBN-13 has 13 characters, got %q", ErrInputLength, s)
+	}
+	return EAN{}.Compute(s)
+}
+
+// VIN - The North American vehicle identification number check digit (49 CFR 565), in position 9
+type VIN struct{}
+
+var (
+	vinLetters      = "ABCDEFGHJKLMNPRSTUVWXYZ" // no I, O or Q
+	vinLetterValues = "12345678123457923456789"
+	vinWeights      = [17]int{8, 7, 6, 5, 4, 3, 2, 10, 0, 9, 8, 7, 6, 5, 4, 3, 2}
+)
+
+func (VIN) Positions(int) []int { return []int{8} }
+
+func (VIN) Compute(s string) (string, error) {
+	for _, r := range s {
+		if r > unicode.MaxASCII {
+			return "", fmt.Errorf("%w: %q", ErrInvalidCharacter, r)
+		}
+	}
+	if len(s) != 17 {
+		return "", fmt.Errorf("%w: a VIN has 17 characters, got %q", ErrInputLength, s)
+	}
+	sum := 0
+	for i, r := range []rune(s) {
+		if i == 8 {
+			continue
+		}
+		value, err := vinValue(r)
+		if err != nil {
+			return "", err
+		}
+		sum += vinWeights[i] * value
+	}
+	return mod11Char(sum % 11), nil
+}
+
+// vinValue - Transliteration of one VIN character
+func vinValue(r rune) (int, error) {
+	if r >= '0' && r <= '9' {
+		return int(r - '0'), nil
+	}
+	i := strings.IndexRune(vinLetters, r)
+	if i < 0 {
+		return 0, fmt.Errorf("%w: %q", ErrInvalidCharacter, r)
+	}
+	return int(vinLetterValues[i] - '0'), nil
+}
+
+// mod11Char - A mod 11 check value as a character, 10 is written as 'X'
+func mod11Char(v int) string {
+	if v == 10 {
+		return "X"
+	}
+	return string(rune('0' + v))
+}
+
+// Verhoeff - The dihedral group D5 scheme, detects all single errors and adjacent transpositions
+type Verhoeff struct{}
+
+var (
+	verhoeffD = [10][10]uint16{
+		{0, 1, 2, 3, 4, 5, 6, 7, 8, 9},
+		{1, 2, 3, 4, 0, 6, 7, 8, 9, 5},
+		{2, 3, 4, 0, 1, 7, 8, 9, 5, 6},
+		{3, 4, 0, 1, 2, 8, 9, 5, 6, 7},
+		{4, 0, 1, 2, 3, 9, 5, 6, 7, 8},
+		{5, 9, 8, 7, 6, 0, 4, 3, 2, 1},
+		{6, 5, 9, 8, 7, 1, 0, 4, 3, 2},
+		{7, 6, 5, 9, 8, 2, 1, 0, 4, 3},
+		{8, 7, 6, 5, 9, 3, 2, 1, 0, 4},
+		{9, 8, 7, 6, 5, 4, 3, 2, 1, 0},
+	}
+	verhoeffP = [8][10]uint16{
+		{0, 1, 2, 3, 4, 5, 6, 7, 8, 9},
+		{1, 5, 7, 6, 2, 8, 3, 0, 9, 4},
+		{5, 8, 0, 3, 7, 9, 6, 1, 4, 2},
+		{8, 9, 1, 6, 0, 4, 3, 5, 2, 7},
+		{9, 4, 5, 3, 1, 2, 6, 8, 7, 0},
+		{4, 2, 8, 6, 5, 7, 3, 9, 0, 1},
+		{2, 7, 9, 3, 8, 0, 6, 4, 1, 5},
+		{7, 0, 4, 6, 9, 1, 3, 2, 5, 8},
+	}
+	verhoeffInv = [10]uint16{0, 4, 3, 2, 1, 5, 6, 7, 8, 9}
+)
+
+func (Verhoeff) Positions(n int) []int { return lastPosition(n) }
+
+func (Verhoeff) Compute(s string) (string, error) {
+	payload, err := payloadDigits(s, lastPosition(len([]rune(s))))
+	if err != nil {
+		return "", err
+	}
+	c := uint16(0)
+	for i := range payload {
+		c = verhoeffD[c][verhoeffP[(i+1)%8][payload[len(payload)-1-i]]]
+	}
+	return string(rune('0' + verhoeffInv[c])), nil
+}
+
+// Damm - The totally anti-symmetric quasigroup scheme of H. M. Damm
+type Damm struct{}
+
+var dammTable = [10][10]uint16{
+	{0, 3, 1, 7, 5, 9, 8, 6, 4, 2},
+	{7, 0, 9, 2, 1, 5, 4, 8, 6, 3},
+	{4, 2, 0, 6, 8, 7, 1, 3, 5, 9},
+	{1, 7, 5, 0, 9, 8, 3, 4, 2, 6},
+	{6, 1, 2, 3, 0, 4, 5, 9, 7, 8},
+	{3, 6, 7, 4, 2, 0, 9, 5, 8, 1},
+	{5, 8, 6, 9, 7, 2, 0, 1, 3, 4},
+	{8, 9, 4, 5, 3, 6, 2, 0, 1, 7},
+	{9, 4, 3, 8, 6, 1, 7, 2, 0, 5},
+	{2, 5, 8, 1, 4, 3, 6, 7, 9, 0},
+}
+
+func (Damm) Positions(n int) []int { return lastPosition(n) }
+
+func (Damm) Compute(s string) (string, error) {
+	payload, err := payloadDigits(s, lastPosition(len([]rune(s))))
+	if err != nil {
+		return "", err
+	}
+	interim := uint16(0)
+	for _, d := range payload {
+		interim = dammTable[interim][d]
+	}
+	return string(rune('0' + interim)), nil
+}
+
+// CheckDigitCipher wraps the FF1 string API for identifiers with check characters:
+// the payload (everything outside the check positions and, for a FixedCheckDigit, the fixed
+// positions) is encrypted over the alphabet, then the check characters are recomputed,
+// so tokens pass the same validation as the original identifiers.
+type CheckDigitCipher struct {
+	ff1   *FF1
+	check CheckDigit
+}
+
+// NewCheckDigitCipher returns a cipher for identifiers validated by check, with payloads over
+// alphabet. minLen and maxLen bound the payload length. The options are passed on to NewFF1.
+func NewCheckDigitCipher(key []byte, alphabet *Alphabet, check CheckDigit, minLen, maxLen, maxTweakLen uint64, opts ...FF1Option) (*CheckDigitCipher, error) {
+	ff1, err := NewFF1(key, alphabet.Radix(), minLen, maxLen, maxTweakLen, append(opts, WithAlphabet(alphabet))...)
+	if err != nil {
+		return nil, err
+	}
+	return &CheckDigitCipher{ff1: ff1, check: check}, nil
+}
+
+// Encrypt encrypts an identifier with valid check characters
+func (c *CheckDigitCipher) Encrypt(tweak []byte, s string) (string, error) {
+	return c.apply(s, func(payload string) (string, error) {
+		return c.ff1.EncryptString(tweak, payload)
+	})
+}
+
+// Decrypt inverts Encrypt
+func (c *CheckDigitCipher) Decrypt(tweak []byte, s string) (string, error) {
+	return c.apply(s, func(payload string) (string, error) {
+		return c.ff1.DecryptString(tweak, payload)
+	})
+}
+
+// apply - Validate s, run op on its payload and recompute the check characters
+func (c *CheckDigitCipher) apply(s string, op func(string) (string, error)) (string, error) {
+	if err := ValidateCheckDigit(c.check, s); err != nil {
+		return "", err
+	}
+
+	runes := []rune(s)
+	positions := c.check.Positions(len(runes))
+	kept := make([]bool, len(runes))
+	for _, pos := range positions {
+		kept[pos] = true
+	}
+	if fixed, ok := c.check.(FixedCheckDigit); ok {
+		for _, pos := range fixed.Fixed(len(runes)) {
+			if pos < 0 || pos >= len(runes) {
+				return "", fmt.Errorf("%w: no fixed position %d in %q", ErrInputLength, pos, s)
+			}
+			kept[pos] = true
+		}
+	}
+	var payload []rune
+	for i, r := range runes {
+		if !kept[i] {
+			payload = append(payload, r)
+		}
+	}
+
+	out, err := op(string(payload))
+	if err != nil {
+		return "", err
+	}
+	outRunes := []rune(out)
+	next := 0
+	for i := range runes {
+		if !kept[i] {
+			runes[i] = outRunes[next]
+			next++
+		}
+	}
+
+	checkChars, err := c.check.Compute(string(runes))
+	if err != nil {
+		return "", err
+	}
+	for i, pos := range positions {
+		runes[pos] = []rune(checkChars)[i]
+	}
+	return string(runes), nil
+}
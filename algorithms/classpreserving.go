@@ -0,0 +1,103 @@
+// classpreserving.go
+package algorithms
+
+import "strings"
+
+// Character classes kept by ClassPreservingCipher, with the letter used for them in layouts
+var (
+	upperClass = mustAlphabet("ABCDEFGHIJKLMNOPQRSTUVWXYZ")
+	lowerClass = mustAlphabet("abcdefghijklmnopqrstuvwxyz")
+	digitClass = mustAlphabet("0123456789")
+)
+
+// ClassPreservingCipher encrypts free-form identifiers without a template: every uppercase letter
+// stays an uppercase letter, every lowercase letter a lowercase letter and every digit a digit,
+// while all other characters pass through unchanged ("Ab3-xY9" keeps its upper-lower-digit-dash-
+// lower-upper-digit shape).
+//
+// The class characters are encrypted together as one mixed-radix value (see MixedRadixCipher).
+// The class layout is appended to the tweak, so equal values always encrypt equally and values
+// with different layouts are encrypted independently. Identifiers with fewer than 1,000,000
+// possible values, about five class characters or less, need WithSmallDomains.
+type ClassPreservingCipher struct {
+	key  []byte
+	opts []FF1Option
+}
+
+// NewClassPreservingCipher returns a class-preserving cipher. The options are passed on to NewFF1.
+func NewClassPreservingCipher(key []byte, opts ...FF1Option) (*ClassPreservingCipher, error) {
+	if err := checkKey(key, opts); err != nil {
+		return nil, err
+	}
+	return &ClassPreservingCipher{key: append([]byte(nil), key...), opts: opts}, nil
+}
+
+// Encrypt encrypts the letters and digits of s in place
+func (c *ClassPreservingCipher) Encrypt(tweak []byte, s string) (string, error) {
+	return c.apply(tweak, s, (*MixedRadixCipher).Encrypt)
+}
+
+// Decrypt inverts Encrypt
+func (c *ClassPreservingCipher) Decrypt(tweak []byte, s string) (string, error) {
+	return c.apply(tweak, s, (*MixedRadixCipher).Decrypt)
+}
+
+// apply - Split s into class numerals and layout, run op on the numerals, and rebuild s
+func (c *ClassPreservingCipher) apply(tweak []byte, s string, op func(*MixedRadixCipher, []byte, []uint16) ([]uint16, error)) (string, error) {
+	runes := []rune(s)
+	classes := make([]*Alphabet, len(runes))
+	var layout strings.Builder // "Aa9-aA9" for "Ab3-xY9"
+	var X []uint16
+	var radices []uint64
+
+	for i, r := range runes {
+		switch {
+		case upperClass.Contains(r):
+			classes[i] = upperClass
+			layout.WriteRune('A')
+		case lowerClass.Contains(r):
+			classes[i] = lowerClass
+			layout.WriteRune('a')
+		case digitClass.Contains(r):
+			classes[i] = digitClass
+			layout.WriteRune('9')
+		default:
+			// Other characters are kept and only contribute to the layout
+			layout.WriteRune(r)
+			continue
+		}
+		num, _ := classes[i].Encode(string(r))
+		X = append(X, num[0])
+		radices = append(radices, classes[i].Radix())
+	}
+
+	if len(X) == 0 {
+		return s, nil
+	}
+
+	// Not cached: the layouts are unbounded and setting up a cipher costs less than running it
+	mixed, err := NewMixedRadixCipher(c.key, radices, c.opts...)
+	if err != nil {
+		return "", err
+	}
+
+	// T || 0x00 || layout
+	Y, err := op(mixed, tweakSuffix(tweak, []byte(layout.String())), X)
+	if err != nil {
+		return "", err
+	}
+
+	next := 0
+	for i := range runes {
+		if classes[i] == nil {
+			continue
+		}
+		chars, err := classes[i].Decode(Y[next : next+1])
+		if err != nil {
+			return "", err
+		}
+		runes[i] = []rune(chars)[0]
+		next++
+	}
+	return string(runes), nil
+}
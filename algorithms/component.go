@@ -3,8 +3,11 @@ package algorithms
 
 import (
 	"crypto/aes"
+	"crypto/cipher"
 	"fmt"
+	"math"
 	"math/big"
+	"math/bits"
 )
 
 // NUM - Bit string to uint64 - could be faster by using bit shifting
@@ -18,20 +21,18 @@ func NUM(X []byte) uint64 {
 
 // NUM - Bit string to big.Int (instead of uint64) to avoid overflow
 func BigNUM(X []byte) *big.Int {
-	// Start with big.Int initialized to 0
-	result := big.NewInt(0)
-
-	for _, b := range X {
-		// Shift the existing value (multiply by 256) and add the current byte (as an integer)
-		result.Lsh(result, 8)                    // Equivalent to result = result * 256 (shift left by 8 bits)
-		result.Add(result, big.NewInt(int64(b))) // Add the byte value (converted to int64)
-	}
+	// A byte string is already the big-endian representation of its integer
+	return new(big.Int).SetBytes(X)
+}
 
-	return result
+// Numeral - The element type of a numeral string.
+// uint16 covers every radix up to 2^16, uint8 is accepted for radix <= 256.
+type Numeral interface {
+	~uint8 | ~uint16
 }
 
 // NUMradix - Numeral string to uint64
-func NUMradix(X []byte, radix uint64) uint64 {
+func NUMradix[T Numeral](X []T, radix uint64) uint64 {
 	x := uint64(0)
 	for _, i := range X {
 		x = x*radix + uint64(i)
@@ -40,49 +41,129 @@ func NUMradix(X []byte, radix uint64) uint64 {
 }
 
 // BigNUMradix - Numeral string to big.Int, with the specified radix
-func BigNUMradix(X []byte, radix uint64) *big.Int {
+func BigNUMradix[T Numeral](X []T, radix uint64) *big.Int {
+	// Short strings fit in a uint64
+	if float64(len(X))*math.Log2(float64(radix)) < 63 {
+		return new(big.Int).SetUint64(NUMradix(X, radix))
+	}
+
 	x := big.NewInt(0) // Start with zero
+	r := new(big.Int).SetUint64(radix)
+	numeral := new(big.Int)
 	for _, i := range X {
-		// Multiply by radix and add the current byte value
-		x.Mul(x, big.NewInt(int64(radix)))
-		x.Add(x, big.NewInt(int64(i)))
+		// Multiply by radix and add the current numeral value
+		x.Mul(x, r)
+		x.Add(x, numeral.SetUint64(uint64(i)))
 	}
 	return x
 }
 
 // STRmRadix - Representation of a uint64 as a string of m numerals in base
-func STRmRadix(x uint64, radix uint64, m int64) []byte {
-	X := make([]byte, m)
+func STRmRadix(x uint64, radix uint64, m int64) []uint16 {
+	X := make([]uint16, m)
+	if radix&(radix-1) == 0 {
+		// Powers of two shift instead of dividing
+		shift := uint(bits.TrailingZeros64(radix))
+		for i := int64(0); i < m; i++ {
+			X[m-1-i] = uint16(x & (radix - 1))
+			x >>= shift
+		}
+		return X
+	}
 	for i := int64(0); i < m; i++ {
-		X[m-1-i] = byte(Mod(x, radix))
-		x = x / radix
+		q := x / radix
+		X[m-1-i] = uint16(x - q*radix) // x mod radix, without a second division
+		x = q
 	}
 	return X
 }
 
 // BigSTRmRadix - Representation of a big.Int as a string of m numerals in base `radix`
-func BigSTRmRadix(x *big.Int, radix uint64, m int64) []byte {
-	xCopy := new(big.Int).Set(x)  // Make a copy of x
-	X := make([]byte, m)          // Create a byte slice to hold the result
-	r := big.NewInt(int64(radix)) // radix as big.Int
+func BigSTRmRadix(x *big.Int, radix uint64, m int64) []uint16 {
+	if x.Sign() >= 0 && x.IsUint64() {
+		return STRmRadix(x.Uint64(), radix, m)
+	}
+
+	xCopy := new(big.Int).Set(x)       // Make a copy of x
+	X := make([]uint16, m)             // Create a numeral slice to hold the result
+	r := new(big.Int).SetUint64(radix) // radix as big.Int
 
 	for i := int64(0); i < m; i++ {
 		mod := new(big.Int)
-		mod.Mod(xCopy, r)             // mod = xCopy % radix
-		X[m-1-i] = byte(mod.Uint64()) // Store the modulus as byte in the slice
-		xCopy.Div(xCopy, r)           // xCopy = xCopy / radix
+		mod.Mod(xCopy, r)               // mod = xCopy % radix
+		X[m-1-i] = uint16(mod.Uint64()) // Store the modulus as a numeral in the slice
+		xCopy.Div(xCopy, r)             // xCopy = xCopy / radix
 	}
 
 	return X
 }
 
+// BigSTRmBytes - [x]^s, the representation of a big.Int as a string of s bytes (STRmRadix with radix 256)
+func BigSTRmBytes(x *big.Int, s int64) []byte {
+	X := make([]byte, s)
+
+	// x mod 256^s, as big-endian bytes
+	xMod := x
+	if x.Sign() < 0 || int64(x.BitLen()) > 8*s {
+		xMod = new(big.Int).Mod(x, new(big.Int).Lsh(big.NewInt(1), uint(8*s)))
+	}
+	xMod.FillBytes(X)
+
+	return X
+}
+
+// BigNUMMixedRadix - Numeral string to big.Int where numeral i has its own radix radices[i].
+// With every radix equal this is BigNUMradix.
+func BigNUMMixedRadix[T Numeral](X []T, radices []uint64) *big.Int {
+	x := big.NewInt(0)
+	r := new(big.Int)
+	numeral := new(big.Int)
+	for i, num := range X {
+		// Multiply by the radix of this position and add the current numeral value
+		x.Mul(x, r.SetUint64(radices[i]))
+		x.Add(x, numeral.SetUint64(uint64(num)))
+	}
+	return x
+}
+
+// BigSTRMixedRadix - Representation of a big.Int as a numeral string where numeral i has radix radices[i].
+// With every radix equal this is BigSTRmRadix.
+func BigSTRMixedRadix(x *big.Int, radices []uint64) []uint16 {
+	xCopy := new(big.Int).Set(x)
+	X := make([]uint16, len(radices))
+	r := new(big.Int)
+	mod := new(big.Int)
+
+	for i := len(radices) - 1; i >= 0; i-- {
+		xCopy.DivMod(xCopy, r.SetUint64(radices[i]), mod) // xCopy, mod = xCopy / radix, xCopy % radix
+		X[i] = uint16(mod.Uint64())
+	}
+
+	return X
+}
+
+// MixedRadixDomain - The number of numeral strings with the given radices: the product of all radices
+func MixedRadixDomain(radices []uint64) *big.Int {
+	n := big.NewInt(1)
+	r := new(big.Int)
+	for _, radix := range radices {
+		n.Mul(n, r.SetUint64(radix))
+	}
+	return n
+}
+
+// PRF - The pseudorandom function of Algorithm 6 (CBC-MAC with a zero IV)
 func PRF(K []byte, X []byte) ([]byte, error) {
 	block, err := aes.NewCipher(K)
 	if err != nil {
 		return nil, err
 	}
+	return prf(block, X)
+}
 
-	blockSize := aes.BlockSize // 16 bytes = 128 bits
+// prf - PRF on an already keyed block cipher, so callers can reuse the key schedule
+func prf(block cipher.Block, X []byte) ([]byte, error) {
+	blockSize := block.BlockSize() // 16 bytes = 128 bits
 	m := len(X) / blockSize
 
 	if len(X)%blockSize != 0 {
@@ -0,0 +1,268 @@
+// date.go
+package algorithms
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	secondsPerDay = 86400
+	dateLayout    = "2006-01-02"
+)
+
+// DatePreserve selects the part of a date a DateCipher keeps
+type DatePreserve int
+
+const (
+	// DatePreserveNone - Any date of the window
+	DatePreserveNone DatePreserve = iota
+	// DatePreserveYear - A date of the same year
+	DatePreserveYear
+	// DatePreserveMonth - A date of the same month of the same year
+	DatePreserveMonth
+)
+
+// DateFormat is the window of a DateCipher: the days Start through End, both included,
+// taken as calendar dates in their own locations.
+type DateFormat struct {
+	Start, End time.Time
+	Preserve   DatePreserve
+}
+
+// DateCipher shifts dates and timestamps to other dates and timestamps inside a window.
+// A date is mapped to its day offset in the window and a timestamp to its second offset,
+// the offset is encrypted with FF1 and cycle walking over the window size (RangeCipher),
+// and the result is turned back into a date or timestamp. With DatePreserveYear or
+// DatePreserveMonth the window is narrowed to the year or month of the input first.
+// Windows of fewer than 1,000,000 values need WithSmallDomains: every date window under
+// 2,700 years and every timestamp window under 12 days. NewDateCipher checks the window in
+// days, so DatePreserveYear and DatePreserveMonth always need it.
+type DateCipher struct {
+	key    []byte
+	opts   []FF1Option
+	format DateFormat
+
+	ciphers lazyCiphers[int64, *RangeCipher] // by window size
+}
+
+// NewDateCipher returns a date cipher for the given window. The options are passed on to NewFF1.
+func NewDateCipher(key []byte, format DateFormat, opts ...FF1Option) (*DateCipher, error) {
+	if dayNumber(format.End) < dayNumber(format.Start) {
+		return nil, fmt.Errorf("%w: window ends before it starts", ErrInvalidDomain)
+	}
+	switch format.Preserve {
+	case DatePreserveNone, DatePreserveYear, DatePreserveMonth:
+	default:
+		return nil, fmt.Errorf("%w: unknown date preservation %d", ErrInvalidFormat, format.Preserve)
+	}
+	c := &DateCipher{key: append([]byte(nil), key...), opts: opts, format: format}
+
+	// Dates have the smallest domains, fail now if the largest of them is rejected
+	days := dayNumber(format.End) - dayNumber(format.Start) + 1
+	switch format.Preserve {
+	case DatePreserveYear:
+		days = minInt64(days, 366)
+	case DatePreserveMonth:
+		days = minInt64(days, 31)
+	}
+	if days < 2 {
+		return c, checkKey(key, opts)
+	}
+	if _, err := c.cipherFor(days); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// EncryptDate encrypts the calendar date of t. The result is at midnight in the location of t.
+func (c *DateCipher) EncryptDate(tweak []byte, t time.Time) (time.Time, error) {
+	return c.applyDate(tweak, t, true)
+}
+
+// DecryptDate inverts EncryptDate
+func (c *DateCipher) DecryptDate(tweak []byte, t time.Time) (time.Time, error) {
+	return c.applyDate(tweak, t, false)
+}
+
+// EncryptTimestamp encrypts t to the second. Fractions of a second and the location are kept;
+// decrypt in the same location, as the year and month of the preserving modes depend on it.
+func (c *DateCipher) EncryptTimestamp(tweak []byte, t time.Time) (time.Time, error) {
+	return c.applyTimestamp(tweak, t, true)
+}
+
+// DecryptTimestamp inverts EncryptTimestamp
+func (c *DateCipher) DecryptTimestamp(tweak []byte, t time.Time) (time.Time, error) {
+	return c.applyTimestamp(tweak, t, false)
+}
+
+// EncryptString encrypts a date (YYYY-MM-DD), an RFC 3339 timestamp or Unix seconds
+// and returns the result in the same layout
+func (c *DateCipher) EncryptString(tweak []byte, s string) (string, error) {
+	return c.applyString(tweak, s, true)
+}
+
+// DecryptString inverts EncryptString
+func (c *DateCipher) DecryptString(tweak []byte, s string) (string, error) {
+	return c.applyString(tweak, s, false)
+}
+
+// applyString - Detect the layout of s, run the date or timestamp cipher and format back
+func (c *DateCipher) applyString(tweak []byte, s string, encrypt bool) (string, error) {
+	if t, err := time.Parse(dateLayout, s); err == nil {
+		out, err := c.applyDate(tweak, t, encrypt)
+		if err != nil {
+			return "", err
+		}
+		return out.Format(dateLayout), nil
+	}
+
+	if sec, err := strconv.ParseInt(s, 10, 64); err == nil {
+		out, err := c.applyTimestamp(tweak, time.Unix(sec, 0).UTC(), encrypt)
+		if err != nil {
+			return "", err
+		}
+		return strconv.FormatInt(out.Unix(), 10), nil
+	}
+
+	if t, err := time.Parse(time.RFC3339Nano, s); err == nil {
+		out, err := c.applyTimestamp(tweak, t, encrypt)
+		if err != nil {
+			return "", err
+		}
+		formatted := out.Format(rfc3339Layout(s))
+		if strings.HasSuffix(s, "-00:00") {
+			// RFC 3339 "unknown local offset", Go formats it as +00:00
+			formatted = strings.TrimSuffix(formatted, "+00:00") + "-00:00"
+		}
+		return formatted, nil
+	}
+
+	return "", fmt.Errorf("%w: %q is not YYYY-MM-DD, RFC 3339 or Unix seconds", ErrFormatMismatch, s)
+}
+
+// rfc3339Layout - The layout that formats a timestamp like s: same fraction digits, same "Z" or "+00:00"
+func rfc3339Layout(s string) string {
+	layout := "2006-01-02T15:04:05"
+	if dot := strings.IndexByte(s, '.'); dot >= 0 {
+		digits := 0
+		for _, r := range s[dot+1:] {
+			if r < '0' || r > '9' {
+				break
+			}
+			digits++
+		}
+		layout += "." + strings.Repeat("0", digits)
+	}
+	if strings.HasSuffix(s, "Z") {
+		return layout + "Z07:00"
+	}
+	return layout + "-07:00"
+}
+
+// applyDate - Encrypt or decrypt the day offset of t
+func (c *DateCipher) applyDate(tweak []byte, t time.Time, encrypt bool) (time.Time, error) {
+	year, month, _ := t.Date()
+	lo, hi := dayNumber(c.format.Start), dayNumber(c.format.End)
+	switch c.format.Preserve {
+	case DatePreserveYear:
+		lo, hi = maxInt64(lo, civilDay(year, 1, 1)), minInt64(hi, civilDay(year+1, 1, 1)-1)
+	case DatePreserveMonth:
+		lo, hi = maxInt64(lo, civilDay(year, month, 1)), minInt64(hi, civilDay(year, month+1, 1)-1)
+	}
+
+	day, err := c.apply(tweak, 'd', dayNumber(t), lo, hi, encrypt)
+	if err != nil {
+		return time.Time{}, err
+	}
+	y, m, d := time.Unix(day*secondsPerDay, 0).UTC().Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, t.Location()), nil
+}
+
+// applyTimestamp - Encrypt or decrypt the second offset of t
+func (c *DateCipher) applyTimestamp(tweak []byte, t time.Time, encrypt bool) (time.Time, error) {
+	year, month, _ := t.Date()
+	loc := t.Location()
+	lo, hi := dayNumber(c.format.Start)*secondsPerDay, (dayNumber(c.format.End)+1)*secondsPerDay-1
+	switch c.format.Preserve {
+	case DatePreserveYear:
+		lo = maxInt64(lo, time.Date(year, 1, 1, 0, 0, 0, 0, loc).Unix())
+		hi = minInt64(hi, time.Date(year+1, 1, 1, 0, 0, 0, 0, loc).Unix()-1)
+	case DatePreserveMonth:
+		lo = maxInt64(lo, time.Date(year, month, 1, 0, 0, 0, 0, loc).Unix())
+		hi = minInt64(hi, time.Date(year, month+1, 1, 0, 0, 0, 0, loc).Unix()-1)
+	}
+
+	sec, err := c.apply(tweak, 's', t.Unix(), lo, hi, encrypt)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return time.Unix(sec, int64(t.Nanosecond())).In(loc), nil
+}
+
+// apply - Encrypt or decrypt v in [lo, hi] as the offset v - lo.
+// The unit and lo are appended to the tweak, so every year or month is encrypted independently.
+func (c *DateCipher) apply(tweak []byte, unit byte, v, lo, hi int64, encrypt bool) (int64, error) {
+	if v < lo || v > hi {
+		return 0, fmt.Errorf("%w: outside the date window", ErrValueOutOfRange)
+	}
+	if lo == hi {
+		// A single date or second maps to itself
+		return v, nil
+	}
+
+	rc, err := c.cipherFor(hi - lo + 1)
+	if err != nil {
+		return 0, err
+	}
+
+	// T || 0x00 || unit || [lo]^8
+	var start [8]byte
+	binary.BigEndian.PutUint64(start[:], uint64(lo))
+	dateTweak := tweakSuffix(tweak, []byte{unit}, start[:])
+
+	op := rc.Decrypt
+	if encrypt {
+		op = rc.Encrypt
+	}
+	y, err := op(dateTweak, big.NewInt(v-lo))
+	if err != nil {
+		return 0, err
+	}
+	return lo + y.Int64(), nil
+}
+
+// cipherFor - The range cipher over n offsets, created on first use
+func (c *DateCipher) cipherFor(n int64) (*RangeCipher, error) {
+	return c.ciphers.get(n, func() (*RangeCipher, error) {
+		return NewRangeCipher(c.key, big.NewInt(n), 0, c.opts...)
+	})
+}
+
+// dayNumber - Days from 1970-01-01 to the calendar date of t
+func dayNumber(t time.Time) int64 {
+	return civilDay(t.Date())
+}
+
+// civilDay - Days from 1970-01-01 to a calendar date, months and days out of range are normalized
+func civilDay(year int, month time.Month, day int) int64 {
+	return time.Date(year, month, day, 0, 0, 0, 0, time.UTC).Unix() / secondsPerDay
+}
+
+func minInt64(a, b int64) int64 {
+	if a < b {
+		return a
+	}
+	return b
+}
+
+func maxInt64(a, b int64) int64 {
+	if a > b {
+		return a
+	}
+	return b
+}
@@ -0,0 +1,283 @@
+// email.go
+package algorithms
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// Lengths from RFC 5321, Section 4.5.3.1
+const (
+	emailMaxLocal = 64
+	emailMaxLabel = 63
+)
+
+// EmailFormat selects the alphabets of an EmailCipher and what it does with the domain.
+// Nil alphabets select the defaults: base64url (letters, digits, '-' and '_') for the
+// local part and base36 (lowercase letters and digits) for domain labels.
+type EmailFormat struct {
+	LocalAlphabet  *Alphabet
+	DomainAlphabet *Alphabet
+	EncryptDomain  bool // encrypt every domain label but the TLD, otherwise keep the domain
+}
+
+// EmailCipher pseudonymizes email addresses into valid addresses.
+// The dots and the '+' of a plus-tag stay in position and the other characters of the
+// local part are encrypted over the local alphabet, with the layout and the (original)
+// domain in the tweak. Domain labels are encrypted one by one with their hyphens in place,
+// so the same domain always maps to the same pseudonym; the TLD is kept. Domain names are
+// case-insensitive: they enter the tweak in lowercase and labels are encrypted in lowercase.
+// Capitals of an encrypted label come back at the same positions, so Decrypt restores the
+// exact address: the label is walked until those positions hold letters again, which takes
+// about (36/26)^k FF1 calls for k capitals over base36.
+// Parts shorter than the FF1 minimum length, local parts like "bob" and labels like "co",
+// are cycle-walked over their few values as with WithSmallDomains, which EmailCipher always
+// sets. That costs up to 2^20/N FF1 calls for N values, about 30ms for a single character,
+// and such parts are only as hard to guess as their N values.
+// Quoted local parts and IP address literals are not supported.
+type EmailCipher struct {
+	local  *alphabetCipher
+	domain *alphabetCipher // nil when the domain is kept
+}
+
+// NewEmailCipher returns an email cipher for the given format. The options are passed on to NewFF1.
+func NewEmailCipher(key []byte, format EmailFormat, opts ...FF1Option) (*EmailCipher, error) {
+	localAlphabet, domainAlphabet := format.LocalAlphabet, format.DomainAlphabet
+	if localAlphabet == nil {
+		localAlphabet = alphabetRegistry["base64url"]
+	}
+	if domainAlphabet == nil {
+		domainAlphabet = alphabetRegistry["base36"]
+	}
+	for _, r := range ".+@" {
+		if localAlphabet.Contains(r) {
+			return nil, fmt.Errorf("%w: the local alphabet contains %q", ErrInvalidFormat, r)
+		}
+	}
+	for _, r := range ".-" {
+		if domainAlphabet.Contains(r) {
+			return nil, fmt.Errorf("%w: the domain alphabet contains %q", ErrInvalidFormat, r)
+		}
+	}
+	// Labels are encrypted in lowercase, capitals would break the case pattern of the output
+	for _, r := range domainAlphabet.String() {
+		if unicode.IsUpper(r) {
+			return nil, fmt.Errorf("%w: the domain alphabet contains the capital %q", ErrInvalidFormat, r)
+		}
+	}
+
+	// Addresses like bob@example.com are too common to turn away
+	opts = append(append([]FF1Option(nil), opts...), WithSmallDomains())
+	local, err := newAlphabetCipher(key, localAlphabet, emailMaxLocal, opts)
+	if err != nil {
+		return nil, err
+	}
+	c := &EmailCipher{local: local}
+	if format.EncryptDomain {
+		if c.domain, err = newAlphabetCipher(key, domainAlphabet, emailMaxLabel, opts); err != nil {
+			return nil, err
+		}
+	}
+	return c, nil
+}
+
+// Encrypt pseudonymizes an email address
+func (c *EmailCipher) Encrypt(tweak []byte, email string) (string, error) {
+	local, domain, err := splitEmail(email)
+	if err != nil {
+		return "", err
+	}
+	encLocal, err := c.local.applyAround(tweakSuffix(tweak, []byte(strings.ToLower(domain))), local, ".+", true)
+	if err != nil {
+		return "", err
+	}
+	encDomain, err := c.applyDomain(tweak, domain, true)
+	if err != nil {
+		return "", err
+	}
+	return encLocal + "@" + encDomain, nil
+}
+
+// Decrypt restores the original address
+func (c *EmailCipher) Decrypt(tweak []byte, email string) (string, error) {
+	local, domain, err := splitEmail(email)
+	if err != nil {
+		return "", err
+	}
+	// The local part was encrypted under the original domain
+	decDomain, err := c.applyDomain(tweak, domain, false)
+	if err != nil {
+		return "", err
+	}
+	decLocal, err := c.local.applyAround(tweakSuffix(tweak, []byte(strings.ToLower(decDomain))), local, ".+", false)
+	if err != nil {
+		return "", err
+	}
+	return decLocal + "@" + decDomain, nil
+}
+
+// applyDomain - Encrypt or decrypt every label of domain but the last one
+func (c *EmailCipher) applyDomain(tweak []byte, domain string, encrypt bool) (string, error) {
+	if c.domain == nil {
+		return domain, nil
+	}
+	labels := strings.Split(domain, ".")
+	if len(labels) < 2 {
+		return "", fmt.Errorf("%w: domain %q has no TLD to keep", ErrFormatMismatch, domain)
+	}
+	labelTweak := tweakSuffix(tweak)
+	for i, label := range labels[:len(labels)-1] {
+		if label == "" || strings.HasPrefix(label, "-") || strings.HasSuffix(label, "-") {
+			return "", fmt.Errorf("%w: invalid domain label %q", ErrFormatMismatch, label)
+		}
+		out, err := c.applyLabel(labelTweak, label, encrypt)
+		if err != nil {
+			return "", err
+		}
+		labels[i] = out
+	}
+	return strings.Join(labels, "."), nil
+}
+
+// applyLabel - Encrypt or decrypt one domain label in lowercase, cycle-walking until every
+// position that held a capital holds a letter again, which is then capitalized. Restricted to
+// the labels with letters at those positions the walk is a permutation, so the case pattern
+// of the input is the case pattern of the output in both directions.
+func (c *EmailCipher) applyLabel(tweak []byte, label string, encrypt bool) (string, error) {
+	runes := []rune(label)
+	capitals := make([]bool, len(runes))
+	for i, r := range runes {
+		if unicode.IsUpper(r) {
+			capitals[i] = true
+			runes[i] = unicode.ToLower(r)
+		}
+	}
+
+	out := string(runes)
+	for walks := 0; walks < DefaultMaxWalks; walks++ {
+		var err error
+		out, err = c.domain.applyAround(tweak, out, "-", encrypt)
+		if err != nil {
+			return "", err
+		}
+		outRunes := []rune(out)
+		accept := true
+		for i, r := range outRunes {
+			if capitals[i] && (!unicode.IsLower(r) || unicode.ToLower(unicode.ToUpper(r)) != r) {
+				accept = false
+				break
+			}
+		}
+		if !accept {
+			continue
+		}
+		for i, r := range outRunes {
+			if capitals[i] {
+				outRunes[i] = unicode.ToUpper(r)
+			}
+		}
+		return string(outRunes), nil
+	}
+	return "", fmt.Errorf("%w: %d steps", ErrWalkLimit, DefaultMaxWalks)
+}
+
+// splitEmail - Local part and domain of a dot-atom address
+func splitEmail(email string) (string, string, error) {
+	at := strings.LastIndexByte(email, '@')
+	if at <= 0 || at == len(email)-1 {
+		return "", "", fmt.Errorf("%w: %q is not an email address", ErrFormatMismatch, email)
+	}
+	local, domain := email[:at], email[at+1:]
+	if strings.HasPrefix(local, ".") || strings.HasSuffix(local, ".") || strings.Contains(local, "..") {
+		return "", "", fmt.Errorf("%w: invalid local part %q", ErrFormatMismatch, local)
+	}
+	return local, domain, nil
+}
+
+// alphabetCipher - The FF1 string API over one alphabet, with a mixed-radix cipher
+// (cycle walking) for strings shorter than the FF1 minimum length
+type alphabetCipher struct {
+	key      []byte
+	opts     []FF1Option
+	alphabet *Alphabet
+	ff1      *FF1
+
+	short lazyCiphers[int, *MixedRadixCipher] // by length
+}
+
+func newAlphabetCipher(key []byte, alphabet *Alphabet, maxLen uint64, opts []FF1Option) (*alphabetCipher, error) {
+	minLen := MinLen(alphabet.Radix())
+	if maxLen < minLen {
+		maxLen = minLen
+	}
+	ff1, err := NewFF1(key, alphabet.Radix(), minLen, maxLen, maxTweakSize, append(opts, WithAlphabet(alphabet))...)
+	if err != nil {
+		return nil, err
+	}
+	return &alphabetCipher{key: append([]byte(nil), key...), opts: opts, alphabet: alphabet, ff1: ff1}, nil
+}
+
+// applyAround - Encrypt or decrypt s with the separator characters kept in position.
+// The positions of the separators are appended to the tweak.
+func (c *alphabetCipher) applyAround(tweak []byte, s, separators string, encrypt bool) (string, error) {
+	runes := []rune(s)
+	var payload []rune
+	layout := make([]byte, 0, len(runes))
+	for _, r := range runes {
+		if strings.ContainsRune(separators, r) {
+			layout = append(layout, string(r)...)
+			continue
+		}
+		payload = append(payload, r)
+		layout = append(layout, '*')
+	}
+
+	out, err := c.apply(tweakSuffix(tweak, layout), string(payload), encrypt)
+	if err != nil {
+		return "", err
+	}
+
+	outRunes := []rune(out)
+	next := 0
+	for i, r := range runes {
+		if !strings.ContainsRune(separators, r) {
+			runes[i] = outRunes[next]
+			next++
+		}
+	}
+	return string(runes), nil
+}
+
+// apply - Encrypt or decrypt s with FF1, or with cycle walking below the FF1 minimum length
+func (c *alphabetCipher) apply(tweak []byte, s string, encrypt bool) (string, error) {
+	n := len([]rune(s))
+	switch {
+	case n == 0:
+		return s, nil
+	case uint64(n) >= c.ff1.minLen && encrypt:
+		return c.ff1.EncryptString(tweak, s)
+	case uint64(n) >= c.ff1.minLen:
+		return c.ff1.DecryptString(tweak, s)
+	}
+
+	mixed, err := c.shortCipher(n)
+	if err != nil {
+		return "", err
+	}
+	if encrypt {
+		return mixed.EncryptString(tweak, s)
+	}
+	return mixed.DecryptString(tweak, s)
+}
+
+// shortCipher - The mixed-radix cipher for strings of n characters, created on first use
+func (c *alphabetCipher) shortCipher(n int) (*MixedRadixCipher, error) {
+	return c.short.get(n, func() (*MixedRadixCipher, error) {
+		alphabets := make([]*Alphabet, n)
+		for i := range alphabets {
+			alphabets[i] = c.alphabet
+		}
+		return NewMixedRadixAlphabetCipher(c.key, alphabets, c.opts...)
+	})
+}
@@ -0,0 +1,50 @@
+// errors.go
+package algorithms
+
+import "errors"
+
+// Sentinel errors returned by the ciphers, match them with errors.Is
+
+// Parameter validation (SP 800-38G Rev.1, Section 5.2)
+var (
+	ErrInvalidRadix       = errors.New("radix must be in [2, 2^16]")
+	ErrDomainTooSmall     = errors.New("radix^minlen must be at least 1,000,000")
+	ErrInvalidMinLen      = errors.New("minlen must be at least 2")
+	ErrInvalidMaxLen      = errors.New("maxlen must be in [minlen, 2^32-1]")
+	ErrInvalidMaxTweakLen = errors.New("maxTlen must be at most 2^32-1")
+	ErrInvalidBlockSize   = errors.New("block cipher must have a 128-bit block")
+	ErrNoBlockCipher      = errors.New("block cipher constructor is nil or returned no cipher")
+	ErrInvalidDomain      = errors.New("domain must hold at least 2 values")
+	ErrInvalidKeySize     = errors.New("key has the wrong size for the cipher")
+)
+
+// Input validation
+var (
+	ErrInputLength       = errors.New("input length is outside [minlen, maxlen]")
+	ErrTweakLength       = errors.New("tweak is longer than maxTlen")
+	ErrInvalidTweak      = errors.New("tweak does not have the size required by the cipher")
+	ErrNumeralOutOfRange = errors.New("numeral is not less than radix")
+	ErrValueOutOfRange   = errors.New("value is outside the domain of the cipher")
+)
+
+// Cycle walking
+var ErrWalkLimit = errors.New("cycle walking did not reach the domain within the walk limit")
+
+// Alphabets
+var (
+	ErrInvalidAlphabet    = errors.New("alphabet must hold between 2 and 2^16 characters")
+	ErrDuplicateCharacter = errors.New("alphabet contains a character more than once")
+	ErrInvalidCharacter   = errors.New("input contains a character that is not in the alphabet")
+	ErrNoAlphabet         = errors.New("cipher was created without an alphabet")
+	ErrUnknownAlphabet    = errors.New("no standard alphabet with that name")
+)
+
+// Formats
+var (
+	ErrInvalidTemplate = errors.New("invalid format template")
+	ErrFormatMismatch  = errors.New("input does not match the format")
+	ErrInvalidFormat   = errors.New("invalid combination of format options")
+)
+
+// Check digits
+var ErrCheckDigit = errors.New("check digit does not match")
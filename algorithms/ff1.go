@@ -2,249 +2,570 @@ package algorithms
 
 import (
 	"crypto/aes"
+	"crypto/cipher"
+	"encoding/binary"
 	"fmt"
 	"math"
 	"math/big"
+	"math/bits"
 )
 
-func Encrypt(key []byte, tweak []byte, X []byte, radix uint64) ([]byte, error) {
-	BigRadix := big.NewInt(int64(radix))
-	block, err := aes.NewCipher(key)
+// FF1 is a reusable FF1 cipher bound to one key and radix.
+// The block cipher (AES unless WithBlockCipher says otherwise) and the constant part of P
+// are prepared once by NewFF1 and never written afterwards, so a single FF1 can be
+// shared between goroutines.
+type FF1 struct {
+	block       cipher.Block
+	radix       uint64
+	minLen      uint64
+	maxLen      uint64
+	maxTweakLen uint64
+
+	// [1]^1 || [2]^1 || [1]^1 || [radix]^3 || [10]^1 - the first 7 bytes of P
+	pPrefix []byte
+
+	tracer       Tracer
+	newBlock     func(key []byte) (cipher.Block, error)
+	alphabet     *Alphabet
+	smallDomains bool
+}
+
+// FF1Option configures optional behaviour of NewFF1
+type FF1Option func(*FF1)
+
+// WithBlockCipher replaces crypto/aes with another 128-bit block cipher, e.g. NewAES.
+// NewFF1 fails with ErrNoBlockCipher for a nil newBlock and with ErrInvalidBlockSize for other block sizes.
+func WithBlockCipher(newBlock func(key []byte) (cipher.Block, error)) FF1Option {
+	return func(f *FF1) {
+		f.newBlock = newBlock
+	}
+}
+
+// WithAlphabet lets the cipher work on character strings with EncryptString and DecryptString.
+// The alphabet must have exactly radix characters.
+func WithAlphabet(a *Alphabet) FF1Option {
+	return func(f *FF1) {
+		f.alphabet = a
+	}
+}
+
+// WithTracer sends the intermediate values of every call to t. A nil t turns tracing off.
+func WithTracer(t Tracer) FF1Option {
+	return func(f *FF1) {
+		if t == nil {
+			t = nopTracer{}
+		}
+		f.tracer = t
+	}
+}
+
+// WithSmallDomains lets RangeCipher, and every cipher built on it, take domains below the
+// 1,000,000 values SP 800-38G requires. FF1 itself still runs on 2^20 values, so each call
+// costs about 2^20/N FF1 calls of cycle walking: milliseconds for N = 1000, a fraction of
+// a second for N = 10. A domain that small can also be tabulated by anyone who can encrypt.
+func WithSmallDomains() FF1Option {
+	return func(f *FF1) {
+		f.smallDomains = true
+	}
+}
+
+// NewFF1 returns an FF1 cipher for the given key and radix.
+// minLen and maxLen bound the length of the numeral strings, maxTweakLen the length of the tweak.
+// The parameters are checked against SP 800-38G Rev.1 and rejected with one of the Err* sentinels.
+func NewFF1(key []byte, radix, minLen, maxLen, maxTweakLen uint64, opts ...FF1Option) (*FF1, error) {
+	if err := validateParams(radix, minLen, maxLen, maxTweakLen); err != nil {
+		return nil, err
+	}
+
+	pPrefix := []byte{1, 2, 1}
+	pPrefix = append(pPrefix, BigSTRmBytes(new(big.Int).SetUint64(radix), 3)...)
+	pPrefix = append(pPrefix, BigSTRmBytes(big.NewInt(10), 1)...)
+
+	f := &FF1{
+		radix:       radix,
+		minLen:      minLen,
+		maxLen:      maxLen,
+		maxTweakLen: maxTweakLen,
+		pPrefix:     pPrefix,
+		tracer:      nopTracer{},
+		newBlock:    aes.NewCipher,
+	}
+	for _, opt := range opts {
+		opt(f)
+	}
+	if f.alphabet != nil && f.alphabet.Radix() != radix {
+		return nil, fmt.Errorf("%w: alphabet has %d characters, radix is %d", ErrInvalidRadix, f.alphabet.Radix(), radix)
+	}
+
+	if f.newBlock == nil {
+		return nil, ErrNoBlockCipher
+	}
+	block, err := f.newBlock(key)
 	if err != nil {
 		return nil, err
 	}
+	if block == nil {
+		return nil, ErrNoBlockCipher
+	}
+	if block.BlockSize() != aes.BlockSize {
+		return nil, fmt.Errorf("%w: got %d bytes", ErrInvalidBlockSize, block.BlockSize())
+	}
+	f.block = block
+	return f, nil
+}
+
+// Encrypt - Algorithm 7: FF1.Encrypt(K, T, X)
+func (f *FF1) Encrypt(tweak []byte, X []uint16) ([]uint16, error) {
+	if err := f.checkInput(tweak, X); err != nil {
+		return nil, err
+	}
+	if Y, ok := f.crypt64(tweak, X, true); ok {
+		return Y, nil
+	}
+	BigRadix := new(big.Int).SetUint64(f.radix)
+
+	trace := f.tracerFor("FF1.Encrypt")
+	trace(-1, "", "radix", f.radix)
+	trace(-1, "", "X", X)
+	trace(-1, "", "T", tweak)
 
-	fmt.Printf("Key is %v\nRadix = %v\nPT is <%v>\nTweak is <%v>\n", key, radix, X, tweak)
 	// Step 1
 	t := uint64(len(tweak))
 	n := uint64(len(X))
 	u := n / 2
 	v := n - u
-	fmt.Printf("\nStep 1: u is %v, v is %v\n", u, v)
+	trace(-1, "1", "u", u)
+	trace(-1, "1", "v", v)
 
 	// Step 2
 	A, B := X[:u], X[u:]
-	fmt.Printf("Step 2: A is %v\nB is %v\n", A, B)
+	trace(-1, "2", "A", A)
+	trace(-1, "2", "B", B)
 
 	// Step 3
-	// Calculate log2(radix):
-	log2Radix := math.Log2(float64(radix))
-
-	b := CeilingDiv(uint64(math.Ceil(float64(v)*log2Radix)), 8)
-	fmt.Printf("Step 3: b is %v\n", b)
+	b := f.b(v)
+	trace(-1, "3", "b", b)
 
 	// Step 4
 	d := 4*CeilingDiv(b, 4) + 4
-	fmt.Printf("Step 4: d is %v\n", d)
+	trace(-1, "4", "d", d)
 
 	// Step 5
-	P := []byte{1, 2, 1}
-	P = append(P, BigSTRmRadix(BigRadix, 256, 3)...)
-	P = append(P, BigSTRmRadix(big.NewInt(10), 256, 1)...)
-	P = append(P, BigSTRmRadix(new(big.Int).SetUint64(Mod(u, 256)), 256, 1)...)
-	P = append(P, BigSTRmRadix(new(big.Int).SetUint64(n), 256, 4)...)
-	P = append(P, BigSTRmRadix(new(big.Int).SetUint64(t), 256, 4)...)
-	fmt.Printf("Step 5: P is %v\n", P)
+	P := f.p(u, n, t)
+	trace(-1, "5", "P", P)
+
+	// radix^u and radix^v, the moduli of Step 6.vi
+	radixAtU := BigPower(BigRadix, new(big.Int).SetUint64(u))
+	radixAtV := BigPower(BigRadix, new(big.Int).SetUint64(v))
+
 	// Step 6
 	for i := int64(0); i < 10; i++ {
-		fmt.Printf("\nRound #%v\n", i)
+		trace(i, "6", "i", i)
 		// Step 6.i
-		Q := tweak
-		Q = append(Q, BigSTRmRadix(big.NewInt(0), 256, ModInt(16-int64(t)-int64(b)-1, 16))...)
-		Q = append(Q, BigSTRmRadix(big.NewInt(i), 256, 1)...)
-		Q = append(Q, BigSTRmRadix(BigNUMradix(B, radix), 256, int64(b))...)
+		Q := f.q(tweak, i, b, B)
+		trace(i, "6.i", "Q", Q)
 
-		fmt.Printf("Step 6.i Q is %v\n", Q)
-
-		R := P
-		R = append(R, Q...)
-
-		// Step 6.ii
-		R, err := PRF(key, R)
+		// Step 6.ii - 6.iii
+		S, err := f.s(P, Q, d, func(step string, j uint64, name string, value interface{}) {
+			f.tracer.Trace(TraceEvent{Op: "FF1.Encrypt", Round: i, Step: step, J: j, Name: name, Value: value})
+		})
 		if err != nil {
-			return []byte{0}, err
+			return nil, err
 		}
-		fmt.Printf("Step 6.ii R is %v\n", R)
-
-		// Step 6.iii
-		S := R
-		fmt.Printf("Step 6.iii: S = R in hex format: %x\n", S)
-
-		for j := uint64(1); j < CeilingDiv(d, 16); j++ {
-			RxorJ, err := XORBytes(R, BigSTRmRadix(new(big.Int).SetUint64(j), 256, 16))
-			fmt.Printf("Step 6.iii iteration %v Step 1: RxorJ is %v\n", j, RxorJ)
-
-			if err != nil {
-				return []byte{0}, err
-			}
-			encryptedBlock := make([]byte, aes.BlockSize)
-			block.Encrypt(encryptedBlock, RxorJ)
-			fmt.Printf("Step 6.iii iteration %v Step 2: encryptedBlock is %s\n", j, encryptedBlock)
-			fmt.Printf("Step 6.iii iteration %v Step 3: S before append is %s\n", j, S)
-			S = append(S, encryptedBlock...)
-			fmt.Printf("Step 6.iii iteration %v Step 4: S after append is %s\n", j, S)
-		}
-		S = S[:d]
-		fmt.Printf("Step 6.iii Final S is %v\n", S)
-		fmt.Printf("Step 6.iii Final S in hex is %x\n", S)
 
 		// Step 6.iv
 		y := BigNUM(S)
-		fmt.Printf("Step 6.iv y is %v\n", y)
+		trace(i, "6.iv", "y", y)
 
 		// Step 6.v
-		mBig := new(big.Int).SetUint64(v)
-		m := int64(v)
+		m, radixAtM := int64(v), radixAtV
 		if i%2 == 0 {
-			mBig = new(big.Int).SetUint64(u)
-			m = int64(u)
+			m, radixAtM = int64(u), radixAtU
 		}
 
-		fmt.Printf("Step 6.v m is %v\n", m)
+		trace(i, "6.v", "m", m)
 
 		// Step 6.vi
-		BigAplusY := BigNUMradix(A, radix)
+		BigAplusY := BigNUMradix(A, f.radix)
 		BigAplusY = BigAplusY.Add(BigAplusY, y)
-		radixAtM := BigPower(BigRadix, mBig)
 		c := BigMod(BigAplusY, radixAtM)
 
-		fmt.Printf("Step 6.vi c is %v\n", c)
+		trace(i, "6.vi", "c", c)
 
 		// Step 6.vii
-		C := BigSTRmRadix(c, radix, m)
+		C := BigSTRmRadix(c, f.radix, m)
 
-		fmt.Printf("Step 6.vii C is %v\n", C)
+		trace(i, "6.vii", "C", C)
 
 		// Step 6.viii
 		A = B
 
-		fmt.Printf("Step 6.viii A is %v\n", A)
+		trace(i, "6.viii", "A", A)
 
 		// Step 6.ix
 		B = C
 
-		fmt.Printf("Step 6.ix B is %v\n", B)
+		trace(i, "6.ix", "B", B)
 
 	}
 	// Step 7
-	A = append(A, B...)
-	return A, nil
+	Y := make([]uint16, 0, n)
+	Y = append(Y, A...)
+	Y = append(Y, B...)
+	return Y, nil
 }
 
-func Decrypt(key []byte, tweak []byte, X []byte, radix uint64) ([]byte, error) {
-	BigRadix := big.NewInt(int64(radix))
-	block, err := aes.NewCipher(key)
-	if err != nil {
+// Decrypt - Algorithm 8: FF1.Decrypt(K, T, Y)
+func (f *FF1) Decrypt(tweak []byte, X []uint16) ([]uint16, error) {
+	if err := f.checkInput(tweak, X); err != nil {
 		return nil, err
 	}
+	if Y, ok := f.crypt64(tweak, X, false); ok {
+		return Y, nil
+	}
+	BigRadix := new(big.Int).SetUint64(f.radix)
+	trace := f.tracerFor("FF1.Decrypt")
+	trace(-1, "", "radix", f.radix)
+	trace(-1, "", "Y", X)
+	trace(-1, "", "T", tweak)
+
 	// Step 1
 	t := uint64(len(tweak))
 	n := uint64(len(X))
 	u := n / 2
 	v := n - u
-	fmt.Printf("\nStep 1: u is %v, v is %v\n", u, v)
+	trace(-1, "1", "u", u)
+	trace(-1, "1", "v", v)
 
 	// Step 2
 	A, B := X[:u], X[u:]
-	fmt.Printf("Step 2: A is %v\nB is %v\n", A, B)
+	trace(-1, "2", "A", A)
+	trace(-1, "2", "B", B)
 
 	// Step 3
-	// Calculate log2(radix):
-	log2Radix := math.Log2(float64(radix))
-
-	b := CeilingDiv(uint64(math.Ceil(float64(v)*log2Radix)), 8)
+	b := f.b(v)
+	trace(-1, "3", "b", b)
 
 	// Step 4
 	d := 4*CeilingDiv(b, 4) + 4
-	fmt.Printf("Step 4: d is %v\n", d)
+	trace(-1, "4", "d", d)
 
 	// Step 5
-	P := []byte{1, 2, 1}
-	P = append(P, BigSTRmRadix(BigRadix, 256, 3)...)
-	P = append(P, BigSTRmRadix(big.NewInt(10), 256, 1)...)
-	P = append(P, BigSTRmRadix(new(big.Int).SetUint64(Mod(u, 256)), 256, 1)...)
-	P = append(P, BigSTRmRadix(new(big.Int).SetUint64(n), 256, 4)...)
-	P = append(P, BigSTRmRadix(new(big.Int).SetUint64(t), 256, 4)...)
-	fmt.Printf("Step 5: P is %v\n", P)
+	P := f.p(u, n, t)
+	trace(-1, "5", "P", P)
+
+	// radix^u and radix^v, the moduli of Step 6.vi
+	radixAtU := BigPower(BigRadix, new(big.Int).SetUint64(u))
+	radixAtV := BigPower(BigRadix, new(big.Int).SetUint64(v))
+
 	// Step 6
 	for i := int64(9); i >= 0; i-- {
-		fmt.Printf("\nRound #%v\n", i)
+		trace(i, "6", "i", i)
 		// Step 6.i
-		Q := tweak
-		Q = append(Q, BigSTRmRadix(big.NewInt(0), 256, ModInt(0-int64(t)-int64(b)-1, 16))...)
-		Q = append(Q, BigSTRmRadix(big.NewInt(i), 256, 1)...)
-		Q = append(Q, BigSTRmRadix(BigNUMradix(A, radix), 256, int64(b))...)
+		Q := f.q(tweak, i, b, A)
+		trace(i, "6.i", "Q", Q)
 
-		fmt.Printf("Step 6.i Q is %v\n", Q)
-
-		R := P
-		R = append(R, Q...)
-
-		// Step 6.ii
-		R, err := PRF(key, R)
+		// Step 6.ii - 6.iii
+		S, err := f.s(P, Q, d, func(step string, j uint64, name string, value interface{}) {
+			f.tracer.Trace(TraceEvent{Op: "FF1.Decrypt", Round: i, Step: step, J: j, Name: name, Value: value})
+		})
 		if err != nil {
-			return []byte{0}, err
+			return nil, err
 		}
-		fmt.Printf("Step 6.ii R is %v\n", R)
-
-		// Step 6.iii
-		S := R
-		fmt.Printf("Step 6.iii: S = R in hex format: %x\n", S)
-
-		for j := uint64(1); j < CeilingDiv(d, 16); j++ {
-			RxorJ, err := XORBytes(R, BigSTRmRadix(new(big.Int).SetUint64(j), 256, 16))
-			fmt.Printf("Step 6.iii iteration %v Step 1: RxorJ is %v\n", j, RxorJ)
-
-			if err != nil {
-				return []byte{0}, err
-			}
-			encryptedBlock := make([]byte, aes.BlockSize)
-			block.Encrypt(encryptedBlock, RxorJ)
-			S = append(S, encryptedBlock...)
-		}
-		S = S[:d]
-		fmt.Printf("Step 6.iii Final S is %v\n", S)
-		fmt.Printf("Step 6.iii Final S in hex is %x\n", S)
 
 		// Step 6.iv
 		y := BigNUM(S)
-		fmt.Printf("Step 6.iv y is %v\n", y)
+		trace(i, "6.iv", "y", y)
 
 		// Step 6.v
-		mBig := new(big.Int).SetUint64(v)
-		m := int64(v)
+		m, radixAtM := int64(v), radixAtV
 		if i%2 == 0 {
-			mBig = new(big.Int).SetUint64(u)
-			m = int64(u)
+			m, radixAtM = int64(u), radixAtU
 		}
 
-		fmt.Printf("Step 6.v m is %v\n", m)
+		trace(i, "6.v", "m", m)
 
 		// Step 6.vi
 
-		BigBminusY := BigNUMradix(B, radix)
+		BigBminusY := BigNUMradix(B, f.radix)
 		BigBminusY = BigBminusY.Sub(BigBminusY, y)
-		c := BigMod(BigBminusY, BigPower(BigRadix, mBig))
+		c := BigMod(BigBminusY, radixAtM)
 
-		fmt.Printf("Step 6.vi c is %v\n", c)
+		trace(i, "6.vi", "c", c)
 
 		// Step 6.vii
-		C := BigSTRmRadix(c, radix, m)
+		C := BigSTRmRadix(c, f.radix, m)
 
-		fmt.Printf("Step 6.vii C is %v\n", C)
+		trace(i, "6.vii", "C", C)
 
 		// Step 6.viii
 		B = A
 
-		fmt.Printf("Step 6.viii B is %v\n", B)
+		trace(i, "6.viii", "B", B)
 
 		// Step 6.ix
 		A = C
 
-		fmt.Printf("Step 6.ix A is %v\n", A)
+		trace(i, "6.ix", "A", A)
 
 	}
 	// Step 7
-	A = append(A, B...)
-	return A, nil
+	Y := make([]uint16, 0, n)
+	Y = append(Y, A...)
+	Y = append(Y, B...)
+	return Y, nil
+}
+
+// EncryptString encrypts a character string over the alphabet given with WithAlphabet
+func (f *FF1) EncryptString(tweak []byte, X string) (string, error) {
+	return f.mapString(X, func(numerals []uint16) ([]uint16, error) {
+		return f.Encrypt(tweak, numerals)
+	})
+}
+
+// DecryptString decrypts a character string over the alphabet given with WithAlphabet
+func (f *FF1) DecryptString(tweak []byte, X string) (string, error) {
+	return f.mapString(X, func(numerals []uint16) ([]uint16, error) {
+		return f.Decrypt(tweak, numerals)
+	})
+}
+
+// mapString - String to numerals, apply, numerals to string
+func (f *FF1) mapString(X string, apply func([]uint16) ([]uint16, error)) (string, error) {
+	if f.alphabet == nil {
+		return "", ErrNoAlphabet
+	}
+	numerals, err := f.alphabet.Encode(X)
+	if err != nil {
+		return "", err
+	}
+	Y, err := apply(numerals)
+	if err != nil {
+		return "", err
+	}
+	return f.alphabet.Decode(Y)
+}
+
+// checkInput - Rejects tweaks and numeral strings that do not fit the parameters given to NewFF1
+func (f *FF1) checkInput(tweak []byte, X []uint16) error {
+	return validateInput(f.radix, f.minLen, f.maxLen, f.maxTweakLen, tweak, X)
+}
+
+// b - Step 3: b = ceil(ceil(v * log2(radix)) / 8)
+func (f *FF1) b(v uint64) uint64 {
+	// Calculate log2(radix):
+	log2Radix := math.Log2(float64(f.radix))
+
+	return CeilingDiv(uint64(math.Ceil(float64(v)*log2Radix)), 8)
+}
+
+// p - Step 5: P = [1]^1 || [2]^1 || [1]^1 || [radix]^3 || [10]^1 || [u mod 256]^1 || [n]^4 || [t]^4
+func (f *FF1) p(u, n, t uint64) []byte {
+	P := make([]byte, aes.BlockSize)
+	copy(P, f.pPrefix)
+	P[7] = byte(Mod(u, 256))
+	binary.BigEndian.PutUint32(P[8:], uint32(n))
+	binary.BigEndian.PutUint32(P[12:], uint32(t))
+	return P
+}
+
+// q - Step 6.i: Q = T || [0]^((-t-b-1) mod 16) || [i]^1 || [NUMradix(X)]^b
+func (f *FF1) q(tweak []byte, i int64, b uint64, X []uint16) []byte {
+	t := int64(len(tweak))
+	Q := make([]byte, 0, len(tweak)+16+int(b))
+	Q = append(Q, tweak...)
+	Q = append(Q, BigSTRmBytes(big.NewInt(0), ModInt(-t-int64(b)-1, 16))...)
+	Q = append(Q, BigSTRmBytes(big.NewInt(i), 1)...)
+	Q = append(Q, BigSTRmBytes(BigNUMradix(X, f.radix), int64(b))...)
+	return Q
+}
+
+// s - Steps 6.ii and 6.iii: R = PRF(P || Q), S = the first d bytes of R || CIPH(R xor [1]^16) || ...
+func (f *FF1) s(P, Q []byte, d uint64, trace func(step string, j uint64, name string, value interface{})) ([]byte, error) {
+	PQ := make([]byte, 0, len(P)+len(Q))
+	PQ = append(PQ, P...)
+	PQ = append(PQ, Q...)
+
+	// Step 6.ii
+	R, err := prf(f.block, PQ)
+	if err != nil {
+		return nil, err
+	}
+	trace("6.ii", 0, "R", R)
+
+	// Step 6.iii
+	S := append([]byte(nil), R...)
+	for j := uint64(1); j < CeilingDiv(d, 16); j++ {
+		RxorJ, err := XORBytes(R, BigSTRmBytes(new(big.Int).SetUint64(j), 16))
+		if err != nil {
+			return nil, err
+		}
+		trace("6.iii", j, "RxorJ", RxorJ)
+		encryptedBlock := make([]byte, aes.BlockSize)
+		f.block.Encrypt(encryptedBlock, RxorJ)
+		trace("6.iii", j, "CIPH", encryptedBlock)
+		S = append(S, encryptedBlock...)
+		trace("6.iii", j, "S", S)
+	}
+	S = S[:d]
+	trace("6.iii", 0, "S", S)
+	return S, nil
+}
+
+// tracerFor - Binds the operation name so the steps only pass round, step, name and value
+func (f *FF1) tracerFor(op string) func(round int64, step, name string, value interface{}) {
+	return func(round int64, step, name string, value interface{}) {
+		f.tracer.Trace(TraceEvent{Op: op, Round: round, Step: step, Name: name, Value: value})
+	}
+}
+
+// crypt64 - Steps 1 to 7 of Algorithms 7 and 8 on uint64 instead of big.Int, for untraced calls
+// where radix^v stays below 2^63. Then b <= 8 and 8 <= d <= 12, so S is R cut to d bytes.
+// Reports false when the input does not qualify and the big.Int path has to run.
+func (f *FF1) crypt64(tweak []byte, X []uint16, encrypt bool) ([]uint16, bool) {
+	if _, traced := f.tracer.(nopTracer); !traced {
+		return nil, false
+	}
+	n := uint64(len(X))
+	u := n / 2
+	v := n - u
+	radixAtU, ok := pow64(f.radix, u)
+	if !ok {
+		return nil, false
+	}
+	radixAtV, ok := pow64(f.radix, v)
+	if !ok {
+		return nil, false
+	}
+
+	t := len(tweak)
+	b := int(f.b(v))
+	d := 4*CeilingDiv(uint64(b), 4) + 4
+
+	// P || Q, only [i]^1 || [NUMradix(B)]^b changes between rounds and it sits in the last block,
+	// so the CBC-MAC state before the last block is computed once
+	pad := int(ModInt(-int64(t)-int64(b)-1, 16))
+	PQ := make([]byte, aes.BlockSize+t+pad+1+b)
+	copy(PQ, f.p(u, n, uint64(t)))
+	copy(PQ[aes.BlockSize:], tweak)
+	last := PQ[len(PQ)-aes.BlockSize:]
+	round := PQ[aes.BlockSize+t+pad:]
+	prefix := make([]byte, aes.BlockSize)
+	for off := 0; off < len(PQ)-aes.BlockSize; off += aes.BlockSize {
+		xor16(prefix, prefix, PQ[off:])
+		f.block.Encrypt(prefix, prefix)
+	}
+	R := make([]byte, aes.BlockSize)
+
+	A, B := NUMradix(X[:u], f.radix), NUMradix(X[u:], f.radix)
+	for k := int64(0); k < 10; k++ {
+		i := k
+		if !encrypt {
+			i = 9 - k
+		}
+		// Q holds B when encrypting and A when decrypting
+		num := B
+		if !encrypt {
+			num = A
+		}
+		round[0] = byte(i)
+		for j := b; j >= 1; j-- {
+			round[j] = byte(num)
+			num >>= 8
+		}
+
+		// PRF(P || Q)
+		xor16(R, prefix, last)
+		f.block.Encrypt(R, R)
+
+		// y mod radix^m, with the d bytes of S as hi || lo and lo the last 8
+		radixAtM := radixAtV
+		if i%2 == 0 {
+			radixAtM = radixAtU
+		}
+		y := bits.Rem64(NUM(R[:d-8]), NUM(R[d-8:d]), radixAtM)
+
+		// A, B, y < radix^m, so one conditional correction replaces the mod
+		if encrypt {
+			c := A + y
+			if c >= radixAtM {
+				c -= radixAtM
+			}
+			A, B = B, c
+		} else {
+			c := B - y
+			if B < y {
+				c += radixAtM
+			}
+			A, B = c, A
+		}
+	}
+
+	Y := make([]uint16, 0, n)
+	Y = append(Y, STRmRadix(A, f.radix, int64(u))...)
+	Y = append(Y, STRmRadix(B, f.radix, int64(v))...)
+	return Y, true
+}
+
+// xor16 - dst = a xor b on the first 16 bytes
+func xor16(dst, a, b []byte) {
+	binary.LittleEndian.PutUint64(dst, binary.LittleEndian.Uint64(a)^binary.LittleEndian.Uint64(b))
+	binary.LittleEndian.PutUint64(dst[8:], binary.LittleEndian.Uint64(a[8:])^binary.LittleEndian.Uint64(b[8:]))
+}
+
+// pow64 - radix^e, false once it reaches 2^63
+func pow64(radix, e uint64) (uint64, bool) {
+	x := uint64(1)
+	for ; e > 0; e-- {
+		hi, lo := bits.Mul64(x, radix)
+		if hi != 0 || lo >= 1<<63 {
+			return 0, false
+		}
+		x = lo
+	}
+	return x, true
+}
+
+// Encrypt - One-shot FF1 encryption; use NewFF1 to encrypt many values under the same key
+func Encrypt(key []byte, tweak []byte, X []uint16, radix uint64) ([]uint16, error) {
+	f, err := NewFF1(key, radix, MinLen(radix), math.MaxUint32, math.MaxUint32)
+	if err != nil {
+		return nil, err
+	}
+	return f.Encrypt(tweak, X)
+}
+
+// Decrypt - One-shot FF1 decryption; use NewFF1 to decrypt many values under the same key
+func Decrypt(key []byte, tweak []byte, X []uint16, radix uint64) ([]uint16, error) {
+	f, err := NewFF1(key, radix, MinLen(radix), math.MaxUint32, math.MaxUint32)
+	if err != nil {
+		return nil, err
+	}
+	return f.Decrypt(tweak, X)
+}
+
+// EncryptString - One-shot FF1 encryption of a character string; the radix is the size of the alphabet.
+// Use NewFF1 with WithAlphabet to encrypt many strings under the same key.
+func EncryptString(key []byte, tweak []byte, X string, alphabet *Alphabet, minLen, maxLen, maxTweakLen uint64) (string, error) {
+	if alphabet == nil {
+		return "", ErrNoAlphabet
+	}
+	f, err := NewFF1(key, alphabet.Radix(), minLen, maxLen, maxTweakLen, WithAlphabet(alphabet))
+	if err != nil {
+		return "", err
+	}
+	return f.EncryptString(tweak, X)
+}
+
+// DecryptString - One-shot FF1 decryption of a character string; the radix is the size of the alphabet.
+// Use NewFF1 with WithAlphabet to decrypt many strings under the same key.
+func DecryptString(key []byte, tweak []byte, X string, alphabet *Alphabet, minLen, maxLen, maxTweakLen uint64) (string, error) {
+	if alphabet == nil {
+		return "", ErrNoAlphabet
+	}
+	f, err := NewFF1(key, alphabet.Radix(), minLen, maxLen, maxTweakLen, WithAlphabet(alphabet))
+	if err != nil {
+		return "", err
+	}
+	return f.DecryptString(tweak, X)
 }
@@ -0,0 +1,58 @@
+package algorithms
+
+import "fmt"
+
+// FF3TweakSize - The original FF3 takes a 64-bit tweak
+const FF3TweakSize = 8
+
+// LegacyFF3 is the original FF3 from SP 800-38G (2016), with a 64-bit tweak.
+// NIST withdrew FF3 after practical attacks on its tweak schedule, so LegacyFF3 can only
+// decrypt: it exists to read old archives and move them to FF1 with MigrateFF3ToFF1.
+//
+// Deprecated: FF3 is not approved; use FF1 or FF3-1 for new data.
+type LegacyFF3 struct {
+	ff3    ff3Core
+	minLen uint64
+	maxLen uint64
+}
+
+// NewLegacyFF3 returns an FF3 cipher for the given AES key and radix.
+//
+// Deprecated: FF3 is not approved; use NewFF1 or NewFF31 for new data.
+func NewLegacyFF3(key []byte, radix, minLen, maxLen uint64) (*LegacyFF3, error) {
+	core, err := newFF3Core(key, radix, minLen, maxLen)
+	if err != nil {
+		return nil, err
+	}
+	return &LegacyFF3{ff3: core, minLen: minLen, maxLen: maxLen}, nil
+}
+
+// Decrypt - FF3.Decrypt(K, T, X) with TL = T[0..31] and TR = T[32..63]
+func (f *LegacyFF3) Decrypt(tweak []byte, X []uint16) ([]uint16, error) {
+	if len(tweak) != FF3TweakSize {
+		return nil, fmt.Errorf("%w: FF3 needs %d bytes, got %d", ErrInvalidTweak, FF3TweakSize, len(tweak))
+	}
+	if err := validateInput(f.ff3.radix, f.minLen, f.maxLen, FF3TweakSize, tweak, X); err != nil {
+		return nil, err
+	}
+	return f.ff3.decrypt(tweak[:4], tweak[4:], X), nil
+}
+
+// MigrateFF3ToFF1 decrypts X with the legacy FF3 cipher and re-encrypts the result with FF1,
+// so the plaintext never leaves the package. Both ciphers must use the same radix.
+// The intermediate plaintext is zeroed before returning.
+func MigrateFF3ToFF1(from *LegacyFF3, fromTweak []byte, to *FF1, toTweak []byte, X []uint16) ([]uint16, error) {
+	if from.ff3.radix != to.radix {
+		return nil, fmt.Errorf("%w: FF3 radix %d, FF1 radix %d", ErrInvalidRadix, from.ff3.radix, to.radix)
+	}
+	plaintext, err := from.Decrypt(fromTweak, X)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		for i := range plaintext {
+			plaintext[i] = 0
+		}
+	}()
+	return to.Encrypt(toTweak, plaintext)
+}
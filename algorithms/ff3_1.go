@@ -0,0 +1,211 @@
+package algorithms
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"fmt"
+	"math/big"
+)
+
+// FF31TweakSize - FF3-1 takes a 56-bit tweak
+const FF31TweakSize = 7
+
+// FF31 is a reusable FF3-1 cipher (SP 800-38G Rev.1, Section 6.3) bound to one key and radix.
+// Like FF1 it holds no mutable state and may be shared between goroutines.
+type FF31 struct {
+	ff3    ff3Core
+	minLen uint64
+	maxLen uint64
+}
+
+// NewFF31 returns an FF3-1 cipher for the given AES key and radix.
+// maxLen may not exceed 2 * floor(log_radix(2^96)), see FF3MaxLen.
+func NewFF31(key []byte, radix, minLen, maxLen uint64) (*FF31, error) {
+	core, err := newFF3Core(key, radix, minLen, maxLen)
+	if err != nil {
+		return nil, err
+	}
+	return &FF31{ff3: core, minLen: minLen, maxLen: maxLen}, nil
+}
+
+// Encrypt - Algorithm 9: FF3-1.Encrypt(K, T, X)
+func (f *FF31) Encrypt(tweak []byte, X []uint16) ([]uint16, error) {
+	if err := f.checkInput(tweak, X); err != nil {
+		return nil, err
+	}
+	TL, TR := ff31TweakHalves(tweak)
+	return f.ff3.encrypt(TL, TR, X), nil
+}
+
+// Decrypt - Algorithm 10: FF3-1.Decrypt(K, T, X)
+func (f *FF31) Decrypt(tweak []byte, X []uint16) ([]uint16, error) {
+	if err := f.checkInput(tweak, X); err != nil {
+		return nil, err
+	}
+	TL, TR := ff31TweakHalves(tweak)
+	return f.ff3.decrypt(TL, TR, X), nil
+}
+
+// checkInput - The tweak must be exactly 56 bits, the numeral string must fit the parameters
+func (f *FF31) checkInput(tweak []byte, X []uint16) error {
+	if len(tweak) != FF31TweakSize {
+		return fmt.Errorf("%w: FF3-1 needs %d bytes, got %d", ErrInvalidTweak, FF31TweakSize, len(tweak))
+	}
+	return validateInput(f.ff3.radix, f.minLen, f.maxLen, FF31TweakSize, tweak, X)
+}
+
+// ff31TweakHalves - Step 3: TL = T[0..27] || 0^4 and TR = T[32..55] || T[28..31] || 0^4
+func ff31TweakHalves(T []byte) ([]byte, []byte) {
+	TL := []byte{T[0], T[1], T[2], T[3] & 0xF0}
+	TR := []byte{T[4], T[5], T[6], (T[3] & 0x0F) << 4}
+	return TL, TR
+}
+
+// FF31Encrypt - One-shot FF3-1 encryption; use NewFF31 to encrypt many values under the same key
+func FF31Encrypt(key []byte, tweak []byte, X []uint16, radix uint64) ([]uint16, error) {
+	f, err := NewFF31(key, radix, MinLen(radix), FF3MaxLen(radix))
+	if err != nil {
+		return nil, err
+	}
+	return f.Encrypt(tweak, X)
+}
+
+// FF31Decrypt - One-shot FF3-1 decryption; use NewFF31 to decrypt many values under the same key
+func FF31Decrypt(key []byte, tweak []byte, X []uint16, radix uint64) ([]uint16, error) {
+	f, err := NewFF31(key, radix, MinLen(radix), FF3MaxLen(radix))
+	if err != nil {
+		return nil, err
+	}
+	return f.Decrypt(tweak, X)
+}
+
+// FF3MaxLen - The largest maxlen allowed for FF3 and FF3-1: 2 * floor(log_radix(2^96))
+func FF3MaxLen(radix uint64) uint64 {
+	if radix < minRadix {
+		return 0
+	}
+	limit := new(big.Int).Lsh(big.NewInt(1), 96)
+	r := new(big.Int).SetUint64(radix)
+	power := new(big.Int).Set(r)
+	k := uint64(0)
+	for power.Cmp(limit) <= 0 {
+		k++
+		power.Mul(power, r)
+	}
+	return 2 * k
+}
+
+// ff3Core - The 8-round Feistel network shared by FF3 and FF3-1, which only differ in
+// how the tweak is split into TL and TR
+type ff3Core struct {
+	block cipher.Block // keyed with REVB(K)
+	radix uint64
+}
+
+func newFF3Core(key []byte, radix, minLen, maxLen uint64) (ff3Core, error) {
+	if err := validateParams(radix, minLen, maxLen, 0); err != nil {
+		return ff3Core{}, err
+	}
+	if maxLen > FF3MaxLen(radix) {
+		return ff3Core{}, fmt.Errorf("%w: FF3 allows at most %d, got %d", ErrInvalidMaxLen, FF3MaxLen(radix), maxLen)
+	}
+	block, err := aes.NewCipher(Rev(key))
+	if err != nil {
+		return ff3Core{}, err
+	}
+	return ff3Core{block: block, radix: radix}, nil
+}
+
+func (c ff3Core) encrypt(TL, TR []byte, X []uint16) []uint16 {
+	BigRadix := new(big.Int).SetUint64(c.radix)
+
+	// Step 1
+	n := len(X)
+	u := (n + 1) / 2
+	v := n - u
+
+	// Step 2
+	A, B := X[:u], X[u:]
+
+	// Step 4
+	for i := 0; i < 8; i++ {
+		// Step 4.i
+		m, W := u, TR
+		if i%2 == 1 {
+			m, W = v, TL
+		}
+
+		// Step 4.ii - 4.iv
+		y := c.y(W, i, B)
+
+		// Step 4.v
+		num := BigNUMradix(Rev(A), c.radix)
+		num.Add(num, y)
+		cc := BigMod(num, BigPower(BigRadix, big.NewInt(int64(m))))
+
+		// Step 4.vi
+		C := Rev(BigSTRmRadix(cc, c.radix, int64(m)))
+
+		// Step 4.vii - 4.viii
+		A, B = B, C
+	}
+
+	// Step 5
+	Y := make([]uint16, 0, n)
+	Y = append(Y, A...)
+	Y = append(Y, B...)
+	return Y
+}
+
+func (c ff3Core) decrypt(TL, TR []byte, X []uint16) []uint16 {
+	BigRadix := new(big.Int).SetUint64(c.radix)
+
+	// Step 1
+	n := len(X)
+	u := (n + 1) / 2
+	v := n - u
+
+	// Step 2
+	A, B := X[:u], X[u:]
+
+	// Step 4
+	for i := 7; i >= 0; i-- {
+		// Step 4.i
+		m, W := u, TR
+		if i%2 == 1 {
+			m, W = v, TL
+		}
+
+		// Step 4.ii - 4.iv
+		y := c.y(W, i, A)
+
+		// Step 4.v
+		num := BigNUMradix(Rev(B), c.radix)
+		num.Sub(num, y)
+		cc := BigMod(num, BigPower(BigRadix, big.NewInt(int64(m))))
+
+		// Step 4.vi
+		C := Rev(BigSTRmRadix(cc, c.radix, int64(m)))
+
+		// Step 4.vii - 4.viii
+		B, A = A, C
+	}
+
+	// Step 5
+	Y := make([]uint16, 0, n)
+	Y = append(Y, A...)
+	Y = append(Y, B...)
+	return Y
+}
+
+// y - Steps 4.ii to 4.iv: P = W xor [i]^4 || [NUMradix(REV(X))]^12, S = REVB(CIPH_REVB(K)(REVB(P))), y = NUM(S)
+func (c ff3Core) y(W []byte, i int, X []uint16) *big.Int {
+	P := make([]byte, 0, aes.BlockSize)
+	P = append(P, W...)
+	P[3] ^= byte(i)
+	P = append(P, BigSTRmBytes(BigNUMradix(Rev(X), c.radix), 12)...)
+
+	S := make([]byte, aes.BlockSize)
+	c.block.Encrypt(S, Rev(P))
+	return BigNUM(Rev(S))
+}
@@ -2,7 +2,6 @@
 package algorithms
 
 import (
-	"errors"
 	"fmt"
 	"math/big"
 )
@@ -11,48 +10,24 @@ import (
 
 // Representation of Character Strings
 
-// StringToNumeralSlice converts a character string to a slice of numerals (`[]uint64`) based on the specified alphabet.
-// The radix is derived from the length of the alphabet. Returns an error if the input contains characters not in the alphabet.
-func StringToNumeralSlice(input, alphabet string) ([]byte, error) {
-	// Create a map for character to numeral values based on the alphabet
-	charToNum := make(map[rune]byte)
-	for i, char := range alphabet {
-		charToNum[char] = byte(i)
+// StringToNumeralSlice converts a character string to a slice of numerals (`[]uint16`) based on the specified alphabet.
+// The radix is derived from the number of characters (runes) in the alphabet. Returns an error if the alphabet is
+// invalid or the input contains characters not in the alphabet.
+func StringToNumeralSlice(input, alphabet string) ([]uint16, error) {
+	a, err := NewAlphabet(alphabet)
+	if err != nil {
+		return nil, err
 	}
-
-	// Convert each character to its numeral representation
-	numerals := make([]byte, len(input))
-	for i, ch := range input {
-		num, exists := charToNum[ch]
-		if !exists {
-			return nil, errors.New("input contains characters not in the specified alphabet")
-		}
-		numerals[i] = num
-	}
-	return numerals, nil
+	return a.Encode(input)
 }
 
-func NumeralSliceToString(numerals []byte, alphabet string) (string, error) {
-	// Validate the alphabet
-	alphabetLength := len(alphabet)
-	if alphabetLength == 0 {
-		return "", errors.New("alphabet cannot be empty")
-	}
-
-	// Ensure all numerals are valid indices within the alphabet
-	for _, numeral := range numerals {
-		if int(numeral) >= alphabetLength {
-			return "", errors.New("numerals contain invalid indices for the alphabet")
-		}
-	}
-
-	// Convert numerals to their corresponding characters
-	result := make([]rune, len(numerals))
-	for i, numeral := range numerals {
-		result[i] = rune(alphabet[numeral])
+// NumeralSliceToString converts a slice of numerals back to a character string over the specified alphabet.
+func NumeralSliceToString(numerals []uint16, alphabet string) (string, error) {
+	a, err := NewAlphabet(alphabet)
+	if err != nil {
+		return "", err
 	}
-
-	return string(result), nil
+	return a.Decode(numerals)
 }
 
 // Basic Operations and Functions
@@ -189,3 +164,12 @@ func XORBytes(a, b []byte) ([]byte, error) {
 	}
 	return result, nil
 }
+
+// Rev - REV(X), the numerals of X in reverse order. On a byte string this is REVB(X).
+func Rev[T Numeral](X []T) []T {
+	Y := make([]T, len(X))
+	for i, x := range X {
+		Y[len(X)-1-i] = x
+	}
+	return Y
+}
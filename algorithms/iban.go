@@ -0,0 +1,203 @@
+// iban.go
+package algorithms
+
+import (
+	"fmt"
+)
+
+// ibanStructures - BBAN structure of every IBAN country (ISO 13616 registry): counts of
+// digits (n), uppercase letters (a) and alphanumerics (c), and the length of the bank and
+// branch identifier at the start of the BBAN
+var ibanStructures = map[string]struct {
+	bban string
+	bank int
+}{
+	"AD": {"4n4n12c", 8}, "AE": {"3n16n", 3}, "AL": {"8n16c", 8}, "AT": {"5n11n", 5},
+	"AZ": {"4a20c", 4}, "BA": {"3n3n8n2n", 6}, "BE": {"3n7n2n", 3}, "BG": {"4a4n2n8c", 8},
+	"BH": {"4a14c", 4}, "BI": {"5n5n11n2n", 10}, "BR": {"8n5n10n1a1c", 13}, "BY": {"4c4n16c", 4},
+	"CH": {"5n12c", 5}, "CR": {"4n14n", 4}, "CY": {"3n5n16c", 8}, "CZ": {"4n6n10n", 4},
+	"DE": {"8n10n", 8}, "DJ": {"5n5n11n2n", 10}, "DK": {"4n9n1n", 4}, "DO": {"4c20n", 4},
+	"EE": {"2n2n11n1n", 2}, "EG": {"4n4n17n", 8}, "ES": {"4n4n1n1n10n", 8}, "FI": {"3n11n", 3},
+	"FK": {"2a12n", 2}, "FO": {"4n9n1n", 4}, "FR": {"5n5n11c2n", 10}, "GB": {"4a6n8n", 10},
+	"GE": {"2a16n", 2}, "GI": {"4a15c", 4}, "GL": {"4n9n1n", 4}, "GR": {"3n4n16c", 7},
+	"GT": {"4c20c", 4}, "HN": {"4a20n", 4}, "HR": {"7n10n", 7}, "HU": {"3n4n1n15n1n", 7},
+	"IE": {"4a6n8n", 10}, "IL": {"3n3n13n", 6}, "IQ": {"4a3n12n", 7}, "IS": {"4n2n6n10n", 4},
+	"IT": {"1a5n5n12c", 11}, "JO": {"4a4n18c", 8}, "KW": {"4a22c", 4}, "KZ": {"3n13c", 3},
+	"LB": {"4n20c", 4}, "LC": {"4a24c", 4}, "LI": {"5n12c", 5}, "LT": {"5n11n", 5},
+	"LU": {"3n13c", 3}, "LV": {"4a13c", 4}, "LY": {"3n3n15n", 6}, "MC": {"5n5n11c2n", 10},
+	"MD": {"2c18c", 2}, "ME": {"3n13n2n", 3}, "MK": {"3n10c2n", 3}, "MN": {"4n12n", 4},
+	"MR": {"5n5n11n2n", 10}, "MT": {"4a5n18c", 9}, "MU": {"4a2n2n12n3n3a", 8}, "NI": {"4a20n", 4},
+	"NL": {"4a10n", 4}, "NO": {"4n6n1n", 4}, "OM": {"3n16c", 3}, "PK": {"4a16c", 4},
+	"PL": {"8n16n", 8}, "PS": {"4a21c", 4}, "PT": {"4n4n11n2n", 8}, "QA": {"4a21c", 4},
+	"RO": {"4a16c", 4}, "RS": {"3n13n2n", 3}, "RU": {"9n5n15c", 14}, "SA": {"2n18c", 2},
+	"SC": {"4a2n2n16n3a", 8}, "SD": {"2n12n", 2}, "SE": {"3n16n1n", 3}, "SI": {"5n8n2n", 5},
+	"SK": {"4n6n10n", 4}, "SM": {"1a5n5n12c", 11}, "SO": {"4n3n12n", 7}, "ST": {"4n4n11n2n", 8},
+	"SV": {"4a20n", 4}, "TL": {"3n14n2n", 3}, "TN": {"2n3n13n2n", 5}, "TR": {"5n1n16c", 5},
+	"UA": {"6n19c", 6}, "VA": {"3n15n", 3}, "VG": {"4a16n", 4}, "XK": {"4n10n2n", 4},
+	"YE": {"4a4n18c", 8},
+}
+
+// ibanAlphanumeric - The 'c' positions of a BBAN; IBANs are written in uppercase
+var ibanAlphanumeric = mustAlphabet("0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZ")
+
+// ibanCountry - A parsed ibanStructures entry, with one alphabet per BBAN position
+type ibanCountry struct {
+	positions []*Alphabet
+	bank      int
+}
+
+var ibanRegistry = func() map[string]ibanCountry {
+	classes := map[byte]*Alphabet{
+		'n': alphabetRegistry["digits"],
+		'a': alphabetRegistry["uppercase"],
+		'c': ibanAlphanumeric,
+	}
+	registry := make(map[string]ibanCountry, len(ibanStructures))
+	for country, structure := range ibanStructures {
+		var positions []*Alphabet
+		count := 0
+		for i := 0; i < len(structure.bban); i++ {
+			if c := structure.bban[i]; c >= '0' && c <= '9' {
+				count = count*10 + int(c-'0')
+				continue
+			}
+			for ; count > 0; count-- {
+				positions = append(positions, classes[structure.bban[i]])
+			}
+		}
+		registry[country] = ibanCountry{positions: positions, bank: structure.bank}
+	}
+	return registry
+}()
+
+// IBANLength returns the length of the IBANs of a country, false for countries without IBANs
+func IBANLength(country string) (int, bool) {
+	c, ok := ibanRegistry[country]
+	return len(c.positions) + 4, ok
+}
+
+// ValidateIBAN checks the country, length, BBAN structure and check digits of an IBAN
+// in electronic format (no spaces)
+func ValidateIBAN(iban string) error {
+	if _, err := parseIBAN(iban); err != nil {
+		return err
+	}
+	return ValidateCheckDigit(IBAN{}, iban)
+}
+
+// parseIBAN - The country entry of an electronic-format IBAN, after checking its structure
+func parseIBAN(iban string) (ibanCountry, error) {
+	if len(iban) < 4 {
+		return ibanCountry{}, fmt.Errorf("%w: %q is too short for an IBAN", ErrFormatMismatch, iban)
+	}
+	country, ok := ibanRegistry[iban[:2]]
+	if !ok {
+		return ibanCountry{}, fmt.Errorf("%w: no IBANs for country %q", ErrFormatMismatch, iban[:2])
+	}
+	if len(iban) != len(country.positions)+4 {
+		return ibanCountry{}, fmt.Errorf("%w: %s IBANs have %d characters, got %d", ErrFormatMismatch, iban[:2], len(country.positions)+4, len(iban))
+	}
+	if !isDigit(rune(iban[2])) || !isDigit(rune(iban[3])) {
+		return ibanCountry{}, fmt.Errorf("%w: check digits of %q", ErrFormatMismatch, iban)
+	}
+	for i, a := range country.positions {
+		if !a.Contains(rune(iban[4+i])) {
+			return ibanCountry{}, fmt.Errorf("%w: %q at position %d of %q", ErrFormatMismatch, iban[4+i], 4+i, iban)
+		}
+	}
+	return country, nil
+}
+
+// IBANFormat configures an IBANCipher
+type IBANFormat struct {
+	KeepBank bool // keep the bank and branch identifier
+}
+
+// IBANCipher encrypts IBANs into IBANs of the same country. The country code (and optionally
+// the bank and branch identifier) is kept, the rest of the BBAN is encrypted position by
+// position within the registry structure (digits, letters or alphanumerics) with a
+// MixedRadixCipher, and the ISO 7064 MOD 97-10 check digits are recomputed. Spaces of the
+// print format stay in place. National check digits inside the BBAN are not recomputed.
+type IBANCipher struct {
+	key    []byte
+	opts   []FF1Option
+	format IBANFormat
+
+	ciphers lazyCiphers[string, *MixedRadixCipher] // by country code
+}
+
+// NewIBANCipher returns an IBAN cipher. The options are passed on to NewFF1.
+func NewIBANCipher(key []byte, format IBANFormat, opts ...FF1Option) (*IBANCipher, error) {
+	if err := checkKey(key, opts); err != nil {
+		return nil, err
+	}
+	return &IBANCipher{key: append([]byte(nil), key...), opts: opts, format: format}, nil
+}
+
+// Encrypt encrypts a valid IBAN
+func (c *IBANCipher) Encrypt(tweak []byte, iban string) (string, error) {
+	return c.apply(tweak, iban, (*MixedRadixCipher).EncryptString)
+}
+
+// Decrypt inverts Encrypt
+func (c *IBANCipher) Decrypt(tweak []byte, iban string) (string, error) {
+	return c.apply(tweak, iban, (*MixedRadixCipher).DecryptString)
+}
+
+// apply - Run op on the encrypted part of the BBAN and recompute the check digits
+func (c *IBANCipher) apply(tweak []byte, iban string, op func(*MixedRadixCipher, []byte, string) (string, error)) (string, error) {
+	// Electronic format, remembering where the spaces were
+	electronic := make([]byte, 0, len(iban))
+	var positions []int
+	for i := 0; i < len(iban); i++ {
+		if iban[i] == ' ' {
+			continue
+		}
+		electronic = append(electronic, iban[i])
+		positions = append(positions, i)
+	}
+	if err := ValidateIBAN(string(electronic)); err != nil {
+		return "", err
+	}
+
+	countryCode := string(electronic[:2])
+	mixed, keep, err := c.cipherFor(countryCode)
+	if err != nil {
+		return "", err
+	}
+
+	// T || 0x00 || country code || kept bank identifier
+	ibanTweak := tweakSuffix(tweak, electronic[:2], electronic[4:4+keep])
+
+	out, err := op(mixed, ibanTweak, string(electronic[4+keep:]))
+	if err != nil {
+		return "", err
+	}
+	copy(electronic[4+keep:], out)
+
+	check, err := IBAN{}.Compute(string(electronic))
+	if err != nil {
+		return "", err
+	}
+	copy(electronic[2:4], check)
+
+	result := []byte(iban)
+	for i, b := range electronic {
+		result[positions[i]] = b
+	}
+	return string(result), nil
+}
+
+// cipherFor - The mixed-radix cipher for the encrypted BBAN positions of a country, created on
+// first use, and the number of kept BBAN characters
+func (c *IBANCipher) cipherFor(countryCode string) (*MixedRadixCipher, int, error) {
+	country := ibanRegistry[countryCode]
+	keep := 0
+	if c.format.KeepBank {
+		keep = country.bank
+	}
+	mixed, err := c.ciphers.get(countryCode, func() (*MixedRadixCipher, error) {
+		return NewMixedRadixAlphabetCipher(c.key, country.positions[keep:], c.opts...)
+	})
+	return mixed, keep, err
+}
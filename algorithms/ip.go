@@ -0,0 +1,147 @@
+// ip.go
+package algorithms
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"fmt"
+	"math/big"
+	"net/netip"
+)
+
+// IPKeySize - Key size of IPCipher: an AES-128 key followed by the 16-byte pad secret
+const IPKeySize = 32
+
+// IPCipher is the prefix-preserving address anonymization of Crypto-PAn (Xu, Fan, Ammar and Moon, 2002):
+// two addresses that share an n-bit prefix still share an n-bit prefix after encryption.
+// Bit i of the address is flipped by the first bit of PRF(first i bits || rest of the pad),
+// so it only depends on the bits before it, and decryption recovers the bits from left to right.
+// IPv4 and IPv6 addresses are encrypted within their own family; zones are kept.
+type IPCipher struct {
+	block cipher.Block
+	pad   []byte
+}
+
+// NewIPCipher returns a Crypto-PAn cipher for a 32-byte key. As in the reference implementation,
+// the first half is the AES key and the pad is the encryption of the second half.
+func NewIPCipher(key []byte) (*IPCipher, error) {
+	if len(key) != IPKeySize {
+		return nil, fmt.Errorf("%w: need %d bytes, got %d", ErrInvalidKeySize, IPKeySize, len(key))
+	}
+	block, err := aes.NewCipher(key[:16])
+	if err != nil {
+		return nil, err
+	}
+	pad, err := prf(block, key[16:])
+	if err != nil {
+		return nil, err
+	}
+	return &IPCipher{block: block, pad: pad}, nil
+}
+
+// Encrypt anonymizes addr
+func (c *IPCipher) Encrypt(addr netip.Addr) (netip.Addr, error) {
+	return c.apply(addr, true)
+}
+
+// Decrypt inverts Encrypt
+func (c *IPCipher) Decrypt(addr netip.Addr) (netip.Addr, error) {
+	return c.apply(addr, false)
+}
+
+// apply - Flip every bit by the PRF of the plaintext bits before it
+func (c *IPCipher) apply(addr netip.Addr, encrypt bool) (netip.Addr, error) {
+	if !addr.IsValid() {
+		return netip.Addr{}, fmt.Errorf("%w: invalid address", ErrFormatMismatch)
+	}
+	in := addr.AsSlice()
+	out := make([]byte, len(in))
+	plain := in // the plaintext bits, known up to bit i when bit i is processed
+	if !encrypt {
+		plain = out
+	}
+
+	X := make([]byte, aes.BlockSize)
+	for i := 0; i < len(in)*8; i++ {
+		// First i plaintext bits, then the pad
+		copy(X, c.pad)
+		for j := 0; j < i/8; j++ {
+			X[j] = plain[j]
+		}
+		if i%8 != 0 {
+			mask := byte(0xff) << (8 - i%8)
+			X[i/8] = plain[i/8]&mask | c.pad[i/8]&^mask
+		}
+
+		Y, err := prf(c.block, X)
+		if err != nil {
+			return netip.Addr{}, err
+		}
+		bit := in[i/8] >> (7 - i%8) & 1
+		out[i/8] |= (bit ^ Y[0]>>7) << (7 - i%8)
+	}
+
+	result, _ := netip.AddrFromSlice(out)
+	return result.WithZone(addr.Zone()), nil
+}
+
+// SubnetCipher encrypts the host bits of addresses in a fixed subnet with FF1 and keeps the
+// network bits, so every address of the subnet maps to another address of the same subnet.
+// Subnets with fewer than 1,000,000 addresses (IPv4 prefixes longer than /12) need WithSmallDomains.
+type SubnetCipher struct {
+	prefix netip.Prefix
+	rc     *RangeCipher
+}
+
+// NewSubnetCipher returns a cipher for the addresses in prefix. The options are passed on to NewFF1.
+func NewSubnetCipher(key []byte, prefix netip.Prefix, opts ...FF1Option) (*SubnetCipher, error) {
+	if !prefix.IsValid() {
+		return nil, fmt.Errorf("%w: invalid prefix", ErrFormatMismatch)
+	}
+	prefix = prefix.Masked()
+	hostBits := prefix.Addr().BitLen() - prefix.Bits()
+	rc, err := NewRangeCipher(key, new(big.Int).Lsh(big.NewInt(1), uint(hostBits)), 0, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return &SubnetCipher{prefix: prefix, rc: rc}, nil
+}
+
+// Prefix - The subnet of the cipher
+func (c *SubnetCipher) Prefix() netip.Prefix {
+	return c.prefix
+}
+
+// Encrypt encrypts the host bits of addr, which must be in the subnet
+func (c *SubnetCipher) Encrypt(tweak []byte, addr netip.Addr) (netip.Addr, error) {
+	return c.apply(addr, func(x *big.Int) (*big.Int, error) {
+		return c.rc.Encrypt(tweak, x)
+	})
+}
+
+// Decrypt inverts Encrypt
+func (c *SubnetCipher) Decrypt(tweak []byte, addr netip.Addr) (netip.Addr, error) {
+	return c.apply(addr, func(x *big.Int) (*big.Int, error) {
+		return c.rc.Decrypt(tweak, x)
+	})
+}
+
+// apply - Split addr into network and host bits and run op on the host bits
+func (c *SubnetCipher) apply(addr netip.Addr, op func(*big.Int) (*big.Int, error)) (netip.Addr, error) {
+	if !c.prefix.Contains(addr.WithZone("")) {
+		return netip.Addr{}, fmt.Errorf("%w: %v is not in %v", ErrValueOutOfRange, addr, c.prefix)
+	}
+	bytes := addr.AsSlice()
+	x := BigNUM(bytes)
+	hostBits := uint(addr.BitLen() - c.prefix.Bits())
+	network := new(big.Int).Rsh(x, hostBits)
+	network.Lsh(network, hostBits)
+
+	y, err := op(new(big.Int).Sub(x, network))
+	if err != nil {
+		return netip.Addr{}, err
+	}
+
+	result, _ := netip.AddrFromSlice(BigSTRmBytes(y.Add(y, network), int64(len(bytes))))
+	return result.WithZone(addr.Zone()), nil
+}
@@ -0,0 +1,198 @@
+// pan.go
+package algorithms
+
+import (
+	"fmt"
+	"math/big"
+)
+
+// Payment card numbers (ISO/IEC 7812)
+const (
+	panMinLen = 12
+	panMaxLen = 19
+
+	// Fewer encrypted digits would leave too few tokens per kept BIN and last four
+	panMinEncrypted = 4
+)
+
+// LuhnMode selects what happens to the Luhn check digit of a PAN
+type LuhnMode int
+
+const (
+	// LuhnRecompute - The token is Luhn-valid, its check digit is computed over the encrypted digits
+	LuhnRecompute LuhnMode = iota
+	// LuhnPreserve - The token keeps the original check digit and is still Luhn-valid
+	LuhnPreserve
+	// LuhnInvalid - The token always fails the Luhn check, so it is never mistaken for a real card
+	LuhnInvalid
+)
+
+// PANFormat selects the digits a PANCipher leaves in the clear. The zero value encrypts
+// everything but the check digit and recomputes it.
+type PANFormat struct {
+	KeepBIN      int  // leading digits kept: 0, 6 or 8
+	KeepLastFour bool // keep the last four digits (including the check digit)
+	Luhn         LuhnMode
+}
+
+// PANCipher encrypts payment card numbers into card numbers of the same length.
+// Spaces and dashes are kept in place. The digits between the kept BIN and the check digit
+// (or the kept last four) are encrypted with FF1 and cycle walking (RangeCipher); when the
+// check digit is kept, the walk continues until the token passes the Luhn check again.
+// The kept digits are appended to the tweak, so a token only decrypts under its own BIN.
+// Encrypting fewer than six digits (an 8-digit BIN and the last four of a 16-digit PAN)
+// needs WithSmallDomains.
+type PANCipher struct {
+	key    []byte
+	format PANFormat
+	opts   []FF1Option
+
+	ciphers lazyCiphers[int, *RangeCipher] // by number of encrypted digits
+}
+
+// NewPANCipher returns a PAN cipher for the given format. The options are passed on to NewFF1.
+func NewPANCipher(key []byte, format PANFormat, opts ...FF1Option) (*PANCipher, error) {
+	if format.KeepBIN != 0 && format.KeepBIN != 6 && format.KeepBIN != 8 {
+		return nil, fmt.Errorf("%w: BIN length must be 0, 6 or 8, got %d", ErrInvalidFormat, format.KeepBIN)
+	}
+	switch format.Luhn {
+	case LuhnRecompute, LuhnPreserve:
+	case LuhnInvalid:
+		if format.KeepLastFour {
+			return nil, fmt.Errorf("%w: LuhnInvalid needs the check digit, which KeepLastFour keeps", ErrInvalidFormat)
+		}
+	default:
+		return nil, fmt.Errorf("%w: unknown Luhn mode %d", ErrInvalidFormat, format.Luhn)
+	}
+	if err := checkKey(key, opts); err != nil {
+		return nil, err
+	}
+	return &PANCipher{key: append([]byte(nil), key...), format: format, opts: opts}, nil
+}
+
+// Encrypt encrypts a Luhn-valid PAN
+func (c *PANCipher) Encrypt(tweak []byte, pan string) (string, error) {
+	return c.apply(tweak, pan, true)
+}
+
+// Decrypt inverts Encrypt
+func (c *PANCipher) Decrypt(tweak []byte, token string) (string, error) {
+	return c.apply(tweak, token, false)
+}
+
+// apply - Encrypt or decrypt the digits of s between the kept head and tail
+func (c *PANCipher) apply(tweak []byte, s string, encrypt bool) (string, error) {
+	runes := []rune(s)
+	var digits []uint16
+	var positions []int
+	for i, r := range runes {
+		switch {
+		case r >= '0' && r <= '9':
+			digits = append(digits, uint16(r-'0'))
+			positions = append(positions, i)
+		case r == ' ' || r == '-':
+		default:
+			return "", fmt.Errorf("%w: %q", ErrInvalidCharacter, r)
+		}
+	}
+
+	n := len(digits)
+	if n < panMinLen || n > panMaxLen {
+		return "", fmt.Errorf("%w: a PAN has %d to %d digits, got %d", ErrInputLength, panMinLen, panMaxLen, n)
+	}
+
+	head, tail := c.format.KeepBIN, 1
+	if c.format.KeepLastFour {
+		tail = 4
+	}
+	m := n - head - tail
+	if m < panMinEncrypted {
+		return "", fmt.Errorf("%w: %d digits left to encrypt, need %d", ErrInputLength, m, panMinEncrypted)
+	}
+
+	// Plaintexts are valid cards, tokens are valid unless the mode says otherwise
+	wantValid := encrypt || c.format.Luhn != LuhnInvalid
+	if luhnValid(digits) != wantValid {
+		return "", fmt.Errorf("%w: Luhn check of %q", ErrCheckDigit, s)
+	}
+
+	// The check digit is kept when it is part of the last four or preserved on purpose
+	keepCheck := c.format.KeepLastFour || c.format.Luhn == LuhnPreserve
+
+	rc, err := c.cipherFor(m)
+	if err != nil {
+		return "", err
+	}
+
+	// T || 0x00 || kept digits
+	kept := make([]byte, 0, head+tail)
+	for _, d := range digits[:head] {
+		kept = append(kept, byte('0'+d))
+	}
+	if keepCheck {
+		for _, d := range digits[n-tail:] {
+			kept = append(kept, byte('0'+d))
+		}
+	}
+	panTweak := tweakSuffix(tweak, kept)
+
+	step := rc.Decrypt
+	if encrypt {
+		step = rc.Encrypt
+	}
+
+	// With a kept check digit, walk until the digits pass the Luhn check again
+	out := append([]uint16(nil), digits...)
+	x, err := CycleWalkUntil(BigNUMradix(digits[head:head+m], 10), DefaultMaxWalks, func(v *big.Int) (*big.Int, error) {
+		return step(panTweak, v)
+	}, func(y *big.Int) bool {
+		copy(out[head:], BigSTRmRadix(y, 10, int64(m)))
+		return !keepCheck || luhnValid(out)
+	})
+	if err != nil {
+		return "", err
+	}
+	copy(out[head:], BigSTRmRadix(x, 10, int64(m)))
+
+	if !keepCheck {
+		check := luhnCheckDigit(out[:n-1])
+		if encrypt && c.format.Luhn == LuhnInvalid {
+			check = (check + 1) % 10
+		}
+		out[n-1] = check
+	}
+
+	for i, d := range out {
+		runes[positions[i]] = rune('0' + d)
+	}
+	return string(runes), nil
+}
+
+// cipherFor - The range cipher over m digits, created on first use
+func (c *PANCipher) cipherFor(m int) (*RangeCipher, error) {
+	return c.ciphers.get(m, func() (*RangeCipher, error) {
+		n := new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(m)), nil)
+		return NewRangeCipher(c.key, n, 0, c.opts...)
+	})
+}
+
+// luhnCheckDigit - The Luhn digit to append to payload
+func luhnCheckDigit(payload []uint16) uint16 {
+	sum := 0
+	for i := len(payload) - 1; i >= 0; i -= 2 {
+		d := int(payload[i]) * 2
+		if d > 9 {
+			d -= 9
+		}
+		sum += d
+		if i > 0 {
+			sum += int(payload[i-1])
+		}
+	}
+	return uint16((10 - sum%10) % 10)
+}
+
+// luhnValid - Whether the last digit of X is the Luhn check digit of the rest
+func luhnValid(X []uint16) bool {
+	return len(X) >= 2 && luhnCheckDigit(X[:len(X)-1]) == X[len(X)-1]
+}
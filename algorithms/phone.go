@@ -0,0 +1,238 @@
+// phone.go
+package algorithms
+
+import (
+	"fmt"
+	"math/big"
+	"strings"
+)
+
+const (
+	e164MaxDigits     = 15
+	phoneMinEncrypted = 4
+	phoneSeparators   = " -.()/"
+)
+
+// countryCodeLengths - ITU-T E.164 country calling codes are prefix-free: 1 and 7 are the
+// one-digit codes, the two-digit prefixes below are complete codes and every other
+// prefix starts a three-digit code
+var countryCodeLengths = map[string]int{
+	"1": 1, "7": 1,
+	"20": 2, "27": 2, "30": 2, "31": 2, "32": 2, "33": 2, "34": 2, "36": 2, "39": 2,
+	"40": 2, "41": 2, "43": 2, "44": 2, "45": 2, "46": 2, "47": 2, "48": 2, "49": 2,
+	"51": 2, "52": 2, "53": 2, "54": 2, "55": 2, "56": 2, "57": 2, "58": 2,
+	"60": 2, "61": 2, "62": 2, "63": 2, "64": 2, "65": 2, "66": 2,
+	"81": 2, "82": 2, "84": 2, "86": 2,
+	"90": 2, "91": 2, "92": 2, "93": 2, "94": 2, "95": 2, "98": 2,
+}
+
+// areaCodeLengths - Countries with a fixed area code length, used when the number is written
+// without separators
+var areaCodeLengths = map[string]int{
+	"1":  3, // NANP
+	"7":  3,
+	"33": 1,
+	"61": 1,
+	"64": 1,
+}
+
+// countryCodeLen - Length of the country calling code at the start of digits
+func countryCodeLen(digits string) (int, error) {
+	if digits == "" || digits[0] == '0' {
+		return 0, fmt.Errorf("%w: no country calling code in %q", ErrFormatMismatch, digits)
+	}
+	if n, ok := countryCodeLengths[digits[:1]]; ok {
+		return n, nil
+	}
+	if len(digits) >= 2 {
+		if n, ok := countryCodeLengths[digits[:2]]; ok {
+			return n, nil
+		}
+	}
+	return 3, nil
+}
+
+// PhoneFormat configures a PhoneCipher
+type PhoneFormat struct {
+	// DefaultCountry is the country calling code of numbers written in national format
+	// (without "+" or "00"), e.g. "1" or "44". Empty accepts international numbers only.
+	DefaultCountry string
+	// KeepAreaCode keeps the area code: the first digit group of the national number as written
+	// ("(415) 555-2671", "+44 20 7946 0958"), or a fixed number of digits for unformatted numbers
+	// of a few countries.
+	KeepAreaCode bool
+}
+
+// PhoneCipher encrypts telephone numbers given as E.164 ("+14155552671"), international
+// ("+44 (0)20 7946 0958", "0044 20 7946 0958") or national ("(415) 555-2671") numbers.
+// The country calling code, the "+" or "00" prefix, a trunk "0" and optionally the area code
+// are kept, the other digits are encrypted with FF1 (fewer than six need WithSmallDomains)
+// and every separator stays in place. The kept digits are part of the tweak. When the area
+// code is encrypted, the encrypted digits are cycle-walked to a first digit other than 0,
+// which would read back as a trunk prefix.
+// Numbering plan rules below the area code (e.g. NANP exchange codes) are not enforced.
+type PhoneCipher struct {
+	format PhoneFormat
+	digits *alphabetCipher
+}
+
+// NewPhoneCipher returns a phone number cipher. The options are passed on to NewFF1.
+func NewPhoneCipher(key []byte, format PhoneFormat, opts ...FF1Option) (*PhoneCipher, error) {
+	if format.DefaultCountry != "" {
+		for _, r := range format.DefaultCountry {
+			if r < '0' || r > '9' {
+				return nil, fmt.Errorf("%w: country calling code %q", ErrInvalidFormat, format.DefaultCountry)
+			}
+		}
+		if n, err := countryCodeLen(format.DefaultCountry); err != nil || n != len(format.DefaultCountry) {
+			return nil, fmt.Errorf("%w: country calling code %q", ErrInvalidFormat, format.DefaultCountry)
+		}
+	}
+	digits, err := newAlphabetCipher(key, alphabetRegistry["digits"], e164MaxDigits, opts)
+	if err != nil {
+		return nil, err
+	}
+	return &PhoneCipher{format: format, digits: digits}, nil
+}
+
+// Encrypt encrypts the subscriber digits of number
+func (c *PhoneCipher) Encrypt(tweak []byte, number string) (string, error) {
+	return c.apply(tweak, number, true)
+}
+
+// Decrypt inverts Encrypt
+func (c *PhoneCipher) Decrypt(tweak []byte, number string) (string, error) {
+	return c.apply(tweak, number, false)
+}
+
+// apply - Find the kept digits of number and encrypt or decrypt the others
+func (c *PhoneCipher) apply(tweak []byte, number string, encrypt bool) (string, error) {
+	runes := []rune(number)
+	var digits []rune
+	var positions []int
+	var groups []int // digit index at which each digit group starts
+	start := 0
+	if strings.HasPrefix(number, "+") {
+		start = 1
+	}
+	for i := start; i < len(runes); i++ {
+		r := runes[i]
+		switch {
+		case r >= '0' && r <= '9':
+			if i == start || !isDigit(runes[i-1]) {
+				groups = append(groups, len(digits))
+			}
+			digits = append(digits, r)
+			positions = append(positions, i)
+		case strings.ContainsRune(phoneSeparators, r):
+		default:
+			return "", fmt.Errorf("%w: %q", ErrInvalidCharacter, r)
+		}
+	}
+
+	// Kept prefix: the international prefix, the country code and a trunk "0"
+	next, country := 0, c.format.DefaultCountry
+	switch {
+	case start == 1 || strings.HasPrefix(string(digits), "00"):
+		if start == 0 {
+			next = 2
+		}
+		n, err := countryCodeLen(string(digits[next:]))
+		if err != nil {
+			return "", err
+		}
+		if next+n > len(digits) {
+			return "", fmt.Errorf("%w: %q ends in the country calling code", ErrFormatMismatch, number)
+		}
+		country = string(digits[next : next+n])
+		next += n
+	case country == "":
+		return "", fmt.Errorf("%w: %q is not an international number and there is no default country", ErrFormatMismatch, number)
+	}
+	if next < len(digits) && digits[next] == '0' {
+		next++
+	}
+	national := next
+	if len(digits)-next+len(country) > e164MaxDigits {
+		return "", fmt.Errorf("%w: more than %d digits in %q", ErrInputLength, e164MaxDigits, number)
+	}
+
+	if c.format.KeepAreaCode {
+		end, err := areaCodeEnd(groups, next, len(digits), country)
+		if err != nil {
+			return "", fmt.Errorf("%w in %q", err, number)
+		}
+		next = end
+	}
+	if len(digits)-next < phoneMinEncrypted {
+		return "", fmt.Errorf("%w: %d digits left to encrypt in %q, need %d", ErrInputLength, len(digits)-next, number, phoneMinEncrypted)
+	}
+
+	// T || 0x00 || country code || 0x00 || kept national digits
+	phoneTweak := tweakSuffix(tweak, []byte(country), []byte{0}, []byte(string(digits[:next])))
+
+	// Without the area code the encrypted digits start the national number, where a leading 0
+	// would read back as a trunk prefix: walk until the first digit is not 0
+	m := len(digits) - next
+	lowest := big.NewInt(0)
+	if next == national {
+		if digits[next] == '0' {
+			return "", fmt.Errorf("%w: national number of %q starts with 00", ErrFormatMismatch, number)
+		}
+		lowest.Exp(big.NewInt(10), big.NewInt(int64(m-1)), nil)
+	}
+	x, _ := new(big.Int).SetString(string(digits[next:]), 10)
+	y, err := CycleWalkUntil(x, DefaultMaxWalks, func(v *big.Int) (*big.Int, error) {
+		out, err := c.digits.apply(phoneTweak, phoneDigits(v, m), encrypt)
+		if err != nil {
+			return nil, err
+		}
+		w, _ := new(big.Int).SetString(out, 10)
+		return w, nil
+	}, func(w *big.Int) bool {
+		return w.Cmp(lowest) >= 0
+	})
+	if err != nil {
+		return "", err
+	}
+
+	for i, r := range phoneDigits(y, m) {
+		runes[positions[next+i]] = r
+	}
+	return string(runes), nil
+}
+
+// phoneDigits - v as m decimal digits
+func phoneDigits(v *big.Int, m int) string {
+	out := make([]byte, m)
+	for i, d := range BigSTRmRadix(v, 10, int64(m)) {
+		out[i] = byte('0' + d)
+	}
+	return string(out)
+}
+
+// areaCodeEnd - Digit index after the area code that starts at digit index from
+func areaCodeEnd(groups []int, from, n int, country string) (int, error) {
+	// The group holding from (or the next one, after a trunk "(0)") when more groups follow
+	for i := range groups {
+		end := n
+		if i+1 < len(groups) {
+			end = groups[i+1]
+		}
+		if end <= from {
+			continue
+		}
+		if end < n {
+			return end, nil
+		}
+		break
+	}
+	if length, ok := areaCodeLengths[country]; ok && from+length <= n {
+		return from + length, nil
+	}
+	return 0, fmt.Errorf("%w: area code is not separated from the subscriber number", ErrFormatMismatch)
+}
+
+func isDigit(r rune) bool {
+	return r >= '0' && r <= '9'
+}
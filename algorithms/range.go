@@ -0,0 +1,123 @@
+// range.go
+package algorithms
+
+import (
+	"fmt"
+	"math/big"
+)
+
+// DefaultMaxWalks - Walk limit used when NewRangeCipher is given 0
+const DefaultMaxWalks = 1 << 20
+
+// CycleWalk applies the permutation step to x until the result lands in [0, n).
+// If step permutes a superset of [0, n), CycleWalk restricted to [0, n) is a permutation of [0, n),
+// and walking with the inverse of step inverts it. Fails with ErrWalkLimit after maxWalks steps.
+func CycleWalk(x, n *big.Int, maxWalks int, step func(*big.Int) (*big.Int, error)) (*big.Int, error) {
+	return CycleWalkUntil(x, maxWalks, step, func(y *big.Int) bool {
+		return y.Cmp(n) < 0
+	})
+}
+
+// CycleWalkUntil is CycleWalk for an arbitrary domain: it applies step until accept holds.
+// Restricted to the accepted values, the walk is a permutation of them.
+func CycleWalkUntil(x *big.Int, maxWalks int, step func(*big.Int) (*big.Int, error), accept func(*big.Int) bool) (*big.Int, error) {
+	y := x
+	for walks := 0; walks < maxWalks; walks++ {
+		var err error
+		y, err = step(y)
+		if err != nil {
+			return nil, err
+		}
+		if accept(y) {
+			return y, nil
+		}
+	}
+	return nil, fmt.Errorf("%w: %d steps", ErrWalkLimit, maxWalks)
+}
+
+// RangeCipher encrypts integers in [0, N) for any N >= 1,000,000, and any N >= 2 with WithSmallDomains.
+// It runs radix-2 FF1 over the smallest bit length that holds N-1 (but at least MinLen(2) bits)
+// and cycle-walks until the result is below N.
+type RangeCipher struct {
+	ff1      *FF1
+	n        *big.Int
+	length   int64 // numerals (bits) per FF1 input
+	maxWalks int
+}
+
+// NewRangeCipher returns a cipher over [0, n). maxWalks bounds the FF1 calls per value,
+// 0 selects DefaultMaxWalks. The options are passed on to NewFF1.
+// Domains below 1,000,000 fail with ErrDomainTooSmall unless the options include WithSmallDomains.
+func NewRangeCipher(key []byte, n *big.Int, maxWalks int, opts ...FF1Option) (*RangeCipher, error) {
+	if n.Cmp(big.NewInt(2)) < 0 {
+		return nil, fmt.Errorf("%w: got %v", ErrInvalidDomain, n)
+	}
+	if maxWalks <= 0 {
+		maxWalks = DefaultMaxWalks
+	}
+
+	length := uint64(new(big.Int).Sub(n, big.NewInt(1)).BitLen())
+	if length < MinLen(2) {
+		length = MinLen(2)
+	}
+	ff1, err := NewFF1(key, 2, length, length, maxTweakSize, opts...)
+	if err != nil {
+		return nil, err
+	}
+	if !ff1.smallDomains && n.Cmp(big.NewInt(minDomain)) < 0 {
+		return nil, fmt.Errorf("%w: domain of %v values, see WithSmallDomains", ErrDomainTooSmall, n)
+	}
+
+	return &RangeCipher{
+		ff1:      ff1,
+		n:        new(big.Int).Set(n),
+		length:   int64(length),
+		maxWalks: maxWalks,
+	}, nil
+}
+
+// Encrypt maps x in [0, N) to another value in [0, N)
+func (r *RangeCipher) Encrypt(tweak []byte, x *big.Int) (*big.Int, error) {
+	return r.walk(x, func(X []uint16) ([]uint16, error) {
+		return r.ff1.Encrypt(tweak, X)
+	})
+}
+
+// Decrypt inverts Encrypt
+func (r *RangeCipher) Decrypt(tweak []byte, y *big.Int) (*big.Int, error) {
+	return r.walk(y, func(X []uint16) ([]uint16, error) {
+		return r.ff1.Decrypt(tweak, X)
+	})
+}
+
+// EncryptUint64 - Encrypt with an empty tweak, for N up to 2^64
+func (r *RangeCipher) EncryptUint64(x uint64) (uint64, error) {
+	y, err := r.Encrypt(nil, new(big.Int).SetUint64(x))
+	if err != nil {
+		return 0, err
+	}
+	return y.Uint64(), nil
+}
+
+// DecryptUint64 - Decrypt with an empty tweak, for N up to 2^64
+func (r *RangeCipher) DecryptUint64(y uint64) (uint64, error) {
+	x, err := r.Decrypt(nil, new(big.Int).SetUint64(y))
+	if err != nil {
+		return 0, err
+	}
+	return x.Uint64(), nil
+}
+
+// walk - Cycle-walks x with FF1 (in either direction) as the permutation of [0, 2^length)
+func (r *RangeCipher) walk(x *big.Int, ff1 func([]uint16) ([]uint16, error)) (*big.Int, error) {
+	if x.Sign() < 0 || x.Cmp(r.n) >= 0 {
+		return nil, fmt.Errorf("%w: %v is outside [0, %v)", ErrValueOutOfRange, x, r.n)
+	}
+	return CycleWalk(x, r.n, r.maxWalks, func(v *big.Int) (*big.Int, error) {
+		Y, err := ff1(BigSTRmRadix(v, 2, r.length))
+		if err != nil {
+			return nil, err
+		}
+		return BigNUMradix(Y, 2), nil
+	})
+}
@@ -0,0 +1,47 @@
+// shared.go
+package algorithms
+
+import "sync"
+
+// Plumbing shared by the format ciphers that create their FF1 instances on first use
+
+// checkKey - Fail on a bad key or option when a format cipher is created rather than on its first call.
+// The callers only create RangeCiphers, so the check builds the radix-2 FF1 a RangeCipher runs on.
+func checkKey(key []byte, opts []FF1Option) error {
+	_, err := NewFF1(key, 2, MinLen(2), MinLen(2), maxTweakSize, opts...)
+	return err
+}
+
+// lazyCiphers - Ciphers created on first use and shared afterwards, safe for concurrent use.
+// K must take a bounded number of values, every one of them keeps its cipher forever.
+type lazyCiphers[K comparable, C interface{}] struct {
+	m sync.Map // K -> C
+}
+
+// get - The cipher for k; create runs when there is none yet
+func (l *lazyCiphers[K, C]) get(k K, create func() (C, error)) (C, error) {
+	if cached, ok := l.m.Load(k); ok {
+		return cached.(C), nil
+	}
+	c, err := create()
+	if err != nil {
+		return c, err
+	}
+	cached, _ := l.m.LoadOrStore(k, c)
+	return cached.(C), nil
+}
+
+// tweakSuffix - T || 0x00 || parts, the caller's tweak extended with the context a format cipher keeps
+func tweakSuffix(tweak []byte, parts ...[]byte) []byte {
+	n := len(tweak) + 1
+	for _, part := range parts {
+		n += len(part)
+	}
+	out := make([]byte, 0, n)
+	out = append(out, tweak...)
+	out = append(out, 0)
+	for _, part := range parts {
+		out = append(out, part...)
+	}
+	return out
+}
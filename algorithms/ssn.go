@@ -0,0 +1,120 @@
+// ssn.go
+package algorithms
+
+import (
+	"fmt"
+	"math/big"
+)
+
+const ssnDigits = 9
+
+// SSNCipher encrypts US Social Security numbers into structurally valid SSNs.
+// FF1 over the nine digits is cycle-walked until the result has an area other than
+// 000, 666 and 900-999, a group other than 00 and a serial other than 0000 (see ValidSSN).
+// The "XXX-XX-XXXX" and the plain nine-digit layouts are kept as given.
+type SSNCipher struct {
+	ff1 *FF1
+}
+
+// NewSSNCipher returns an SSN cipher. The options are passed on to NewFF1.
+func NewSSNCipher(key []byte, opts ...FF1Option) (*SSNCipher, error) {
+	ff1, err := NewFF1(key, 10, ssnDigits, ssnDigits, maxTweakSize, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return &SSNCipher{ff1: ff1}, nil
+}
+
+// Encrypt encrypts a valid SSN
+func (c *SSNCipher) Encrypt(tweak []byte, ssn string) (string, error) {
+	return c.apply(ssn, func(X []uint16) ([]uint16, error) {
+		return c.ff1.Encrypt(tweak, X)
+	})
+}
+
+// Decrypt inverts Encrypt
+func (c *SSNCipher) Decrypt(tweak []byte, ssn string) (string, error) {
+	return c.apply(ssn, func(X []uint16) ([]uint16, error) {
+		return c.ff1.Decrypt(tweak, X)
+	})
+}
+
+// apply - Cycle-walk the digits of ssn with ff1 (in either direction) until they are valid again
+func (c *SSNCipher) apply(ssn string, ff1 func([]uint16) ([]uint16, error)) (string, error) {
+	X, dashed, err := parseSSN(ssn)
+	if err != nil {
+		return "", err
+	}
+	if !validSSNDigits(X) {
+		return "", fmt.Errorf("%w: %q is not a valid SSN", ErrValueOutOfRange, ssn)
+	}
+
+	y, err := CycleWalkUntil(BigNUMradix(X, 10), DefaultMaxWalks, func(v *big.Int) (*big.Int, error) {
+		Y, err := ff1(BigSTRmRadix(v, 10, ssnDigits))
+		if err != nil {
+			return nil, err
+		}
+		return BigNUMradix(Y, 10), nil
+	}, ValidSSNNumber)
+	if err != nil {
+		return "", err
+	}
+
+	return formatSSN(BigSTRmRadix(y, 10, ssnDigits), dashed), nil
+}
+
+// ValidSSN reports whether ssn ("XXX-XX-XXXX" or nine digits) follows the SSA numbering rules
+func ValidSSN(ssn string) bool {
+	X, _, err := parseSSN(ssn)
+	return err == nil && validSSNDigits(X)
+}
+
+// ValidSSNNumber is ValidSSN for the SSN read as a nine-digit integer
+func ValidSSNNumber(x *big.Int) bool {
+	if x.Sign() < 0 || !x.IsUint64() {
+		return false
+	}
+	v := x.Uint64()
+	area, group, serial := v/1000000, v/10000%100, v%10000
+	return area != 0 && area != 666 && area < 900 && group != 0 && serial != 0
+}
+
+// validSSNDigits - ValidSSNNumber of a nine-digit numeral string
+func validSSNDigits(X []uint16) bool {
+	return ValidSSNNumber(BigNUMradix(X, 10))
+}
+
+// parseSSN - The digits of ssn and whether it uses the dashed layout
+func parseSSN(ssn string) ([]uint16, bool, error) {
+	dashed := len(ssn) == ssnDigits+2
+	if !dashed && len(ssn) != ssnDigits {
+		return nil, false, fmt.Errorf("%w: %q is not XXX-XX-XXXX", ErrFormatMismatch, ssn)
+	}
+	X := make([]uint16, 0, ssnDigits)
+	for i := 0; i < len(ssn); i++ {
+		r := ssn[i]
+		if dashed && (i == 3 || i == 6) {
+			if r != '-' {
+				return nil, false, fmt.Errorf("%w: %q is not XXX-XX-XXXX", ErrFormatMismatch, ssn)
+			}
+			continue
+		}
+		if r < '0' || r > '9' {
+			return nil, false, fmt.Errorf("%w: %q is not XXX-XX-XXXX", ErrFormatMismatch, ssn)
+		}
+		X = append(X, uint16(r-'0'))
+	}
+	return X, dashed, nil
+}
+
+// formatSSN - Inverse of parseSSN
+func formatSSN(X []uint16, dashed bool) string {
+	out := make([]byte, 0, ssnDigits+2)
+	for i, d := range X {
+		if dashed && (i == 3 || i == 5) {
+			out = append(out, '-')
+		}
+		out = append(out, byte('0'+d))
+	}
+	return string(out)
+}
@@ -0,0 +1,94 @@
+// tracer.go
+package algorithms
+
+import (
+	"crypto/aes"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// TraceEvent is one intermediate value of a cipher, named as in SP 800-38G.
+// Round is the Feistel round for Step 6 values and -1 for everything else,
+// J is the block counter j of Step 6.iii and 0 for everything else.
+type TraceEvent struct {
+	Op    string // "FF1.Encrypt", "FF1.Decrypt", ...
+	Round int64
+	Step  string // "1", "5", "6.iii", ...
+	J     uint64
+	Name  string // "radix", "X", "Y", "T", "u", "v", "b", "d", "P", "Q", "R", "RxorJ", "CIPH", "S", "y", "m", "c", "C", "A", "B"
+	Value interface{}
+}
+
+// Tracer receives the intermediate values of every call.
+// Values may contain plaintext, only trace in tests and teaching material.
+type Tracer interface {
+	Trace(ev TraceEvent)
+}
+
+// nopTracer - The default tracer, discards everything
+type nopTracer struct{}
+
+func (nopTracer) Trace(TraceEvent) {}
+
+// TextTracer writes the step-by-step dump ff1.go used to print to stdout, minus the key:
+// the "Radix =", "PT is" and "Tweak is" header, "Step 1: u is 5, v is 5" and so on up to
+// "Step 6.ix", with a "Round #<i>" header before every Feistel round. As before, decryption
+// leaves out the header, b and all but the first line of every block of Step 6.iii.
+type TextTracer struct {
+	W io.Writer
+}
+
+// NewTextTracer returns a TextTracer writing to w
+func NewTextTracer(w io.Writer) *TextTracer {
+	return &TextTracer{W: w}
+}
+
+func (t *TextTracer) Trace(ev TraceEvent) {
+	decrypt := ev.Op == "FF1.Decrypt"
+	switch {
+	case ev.Step == "":
+		if decrypt {
+			return
+		}
+		switch ev.Name {
+		case "radix":
+			fmt.Fprintf(t.W, "Radix = %v\n", ev.Value)
+		case "X":
+			fmt.Fprintf(t.W, "PT is <%v>\n", ev.Value)
+		case "T":
+			fmt.Fprintf(t.W, "Tweak is <%v>\n", ev.Value)
+		}
+	case ev.Step == "1" && ev.Name == "u":
+		fmt.Fprintf(t.W, "\nStep 1: u is %v, ", ev.Value)
+	case ev.Step == "1":
+		fmt.Fprintf(t.W, "v is %v\n", ev.Value)
+	case ev.Step == "2" && ev.Name == "B":
+		fmt.Fprintf(t.W, "B is %v\n", ev.Value)
+	case ev.Step == "3" && decrypt:
+	case ev.Step == "6":
+		fmt.Fprintf(t.W, "\nRound #%v\n", ev.Value)
+	case ev.Step == "6.ii":
+		fmt.Fprintf(t.W, "Step 6.ii R is %v\n", ev.Value)
+		fmt.Fprintf(t.W, "Step 6.iii: S = R in hex format: %x\n", ev.Value)
+	case ev.J > 0:
+		switch {
+		case ev.Name == "RxorJ":
+			fmt.Fprintf(t.W, "Step 6.iii iteration %v Step 1: RxorJ is %v\n", ev.J, ev.Value)
+		case decrypt:
+		case ev.Name == "CIPH":
+			fmt.Fprintf(t.W, "Step 6.iii iteration %v Step 2: encryptedBlock is %s\n", ev.J, ev.Value)
+		case ev.Name == "S":
+			S := ev.Value.([]byte)
+			fmt.Fprintf(t.W, "Step 6.iii iteration %v Step 3: S before append is %s\n", ev.J, S[:len(S)-aes.BlockSize])
+			fmt.Fprintf(t.W, "Step 6.iii iteration %v Step 4: S after append is %s\n", ev.J, S)
+		}
+	case ev.Step == "6.iii":
+		fmt.Fprintf(t.W, "Step 6.iii Final S is %v\n", ev.Value)
+		fmt.Fprintf(t.W, "Step 6.iii Final S in hex is %x\n", ev.Value)
+	case strings.HasPrefix(ev.Step, "6."):
+		fmt.Fprintf(t.W, "Step %s %s is %v\n", ev.Step, ev.Name, ev.Value)
+	default:
+		fmt.Fprintf(t.W, "Step %s: %s is %v\n", ev.Step, ev.Name, ev.Value)
+	}
+}
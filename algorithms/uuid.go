@@ -0,0 +1,141 @@
+// uuid.go
+package algorithms
+
+import (
+	"encoding/hex"
+	"fmt"
+	"strings"
+)
+
+// UUIDCipher encrypts UUIDs (RFC 4122 / RFC 9562) into UUIDs of the same version and variant.
+// The version nibble and the variant bits (one to three, by variant) are kept and the
+// remaining 121 to 123 bits, 122 for RFC 4122 UUIDs, are encrypted with radix-2 FF1.
+// The version and variant are part of the tweak.
+type UUIDCipher struct {
+	ff1 *FF1
+}
+
+// NewUUIDCipher returns a UUID cipher. The options are passed on to NewFF1.
+func NewUUIDCipher(key []byte, opts ...FF1Option) (*UUIDCipher, error) {
+	ff1, err := NewFF1(key, 2, 128-4-3, 128-4-1, maxTweakSize, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return &UUIDCipher{ff1: ff1}, nil
+}
+
+// Encrypt encrypts a UUID in canonical ("xxxxxxxx-xxxx-xxxx-xxxx-xxxxxxxxxxxx") or braced form,
+// in lowercase or uppercase, and returns it in the same form
+func (c *UUIDCipher) Encrypt(tweak []byte, s string) (string, error) {
+	return c.applyString(tweak, s, c.EncryptUUID)
+}
+
+// Decrypt inverts Encrypt
+func (c *UUIDCipher) Decrypt(tweak []byte, s string) (string, error) {
+	return c.applyString(tweak, s, c.DecryptUUID)
+}
+
+// EncryptUUID encrypts a UUID in binary form
+func (c *UUIDCipher) EncryptUUID(tweak []byte, u [16]byte) ([16]byte, error) {
+	return c.apply(tweak, u, c.ff1.Encrypt)
+}
+
+// DecryptUUID inverts EncryptUUID
+func (c *UUIDCipher) DecryptUUID(tweak []byte, u [16]byte) ([16]byte, error) {
+	return c.apply(tweak, u, c.ff1.Decrypt)
+}
+
+// apply - Run ff1 on the bits of u outside the version nibble and the variant bits
+func (c *UUIDCipher) apply(tweak []byte, u [16]byte, ff1 func([]byte, []uint16) ([]uint16, error)) ([16]byte, error) {
+	variantBits := uuidVariantBits(u[8])
+	kept := func(bit int) bool {
+		return bit >= 48 && bit < 52 || bit >= 64 && bit < 64+variantBits
+	}
+
+	X := make([]uint16, 0, 128-4-variantBits)
+	for bit := 0; bit < 128; bit++ {
+		if !kept(bit) {
+			X = append(X, uint16(u[bit/8]>>(7-bit%8)&1))
+		}
+	}
+
+	// T || 0x00 || version || variant byte with the free bits cleared
+	uuidTweak := tweakSuffix(tweak, []byte{u[6] >> 4, u[8] &^ (0xff >> variantBits)})
+
+	Y, err := ff1(uuidTweak, X)
+	if err != nil {
+		return [16]byte{}, err
+	}
+
+	out := u
+	next := 0
+	for bit := 0; bit < 128; bit++ {
+		if kept(bit) {
+			continue
+		}
+		mask := byte(1) << (7 - bit%8)
+		out[bit/8] &^= mask
+		if Y[next] == 1 {
+			out[bit/8] |= mask
+		}
+		next++
+	}
+	return out, nil
+}
+
+// uuidVariantBits - Length of the variant field in the top bits of octet 8:
+// 0xx (NCS) is one bit, 10x (RFC 4122) two and 110/111 (Microsoft, reserved) three
+func uuidVariantBits(octet byte) int {
+	switch {
+	case octet&0x80 == 0:
+		return 1
+	case octet&0x40 == 0:
+		return 2
+	}
+	return 3
+}
+
+// applyString - Parse s, run op and write the result in the form of s
+func (c *UUIDCipher) applyString(tweak []byte, s string, op func([]byte, [16]byte) ([16]byte, error)) (string, error) {
+	u, braced, upper, err := parseUUID(s)
+	if err != nil {
+		return "", err
+	}
+	out, err := op(tweak, u)
+	if err != nil {
+		return "", err
+	}
+	return formatUUID(out, braced, upper), nil
+}
+
+// parseUUID - The bytes of a canonical or braced UUID, the form it is in
+func parseUUID(s string) (u [16]byte, braced, upper bool, err error) {
+	if strings.HasPrefix(s, "{") && strings.HasSuffix(s, "}") {
+		braced, s = true, s[1:len(s)-1]
+	}
+	if len(s) != 36 || s[8] != '-' || s[13] != '-' || s[18] != '-' || s[23] != '-' {
+		return u, false, false, fmt.Errorf("%w: %q is not a UUID", ErrFormatMismatch, s)
+	}
+	upper = strings.ContainsAny(s, "ABCDEF")
+	if upper && strings.ContainsAny(s, "abcdef") {
+		return u, false, false, fmt.Errorf("%w: %q mixes upper and lower case", ErrFormatMismatch, s)
+	}
+	hexDigits := s[:8] + s[9:13] + s[14:18] + s[19:23] + s[24:]
+	if _, err := hex.Decode(u[:], []byte(hexDigits)); err != nil {
+		return u, false, false, fmt.Errorf("%w: %q is not a UUID", ErrFormatMismatch, s)
+	}
+	return u, braced, upper, nil
+}
+
+// formatUUID - Inverse of parseUUID
+func formatUUID(u [16]byte, braced, upper bool) string {
+	h := hex.EncodeToString(u[:])
+	s := h[:8] + "-" + h[8:12] + "-" + h[12:16] + "-" + h[16:20] + "-" + h[20:]
+	if upper {
+		s = strings.ToUpper(s)
+	}
+	if braced {
+		s = "{" + s + "}"
+	}
+	return s
+}
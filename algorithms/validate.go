@@ -0,0 +1,67 @@
+// validate.go
+package algorithms
+
+import (
+	"fmt"
+	"math"
+)
+
+// Parameter and input checks from SP 800-38G Rev.1
+
+const (
+	minRadix     = 2
+	maxRadix     = 1 << 16
+	minDomain    = 1000000 // radix^minlen >= 1,000,000
+	maxLenLimit  = math.MaxUint32
+	maxTweakSize = math.MaxUint32
+)
+
+// MinLen - The smallest minlen (at least 2) for which radix^minlen >= 1,000,000
+func MinLen(radix uint64) uint64 {
+	if radix < minRadix {
+		return 2
+	}
+	minLen := uint64(2)
+	for Power(radix, minLen) < minDomain {
+		minLen++
+	}
+	return minLen
+}
+
+// validateParams - Checks radix, minlen, maxlen and maxTlen
+func validateParams(radix, minLen, maxLen, maxTweakLen uint64) error {
+	if radix < minRadix || radix > maxRadix {
+		return fmt.Errorf("%w: got %d", ErrInvalidRadix, radix)
+	}
+	if minLen < 2 {
+		return fmt.Errorf("%w: got %d", ErrInvalidMinLen, minLen)
+	}
+	// radix^minlen, computed in floating point to avoid overflowing uint64
+	if float64(minLen)*math.Log10(float64(radix)) < 6 && Power(radix, minLen) < minDomain {
+		return fmt.Errorf("%w: %d^%d", ErrDomainTooSmall, radix, minLen)
+	}
+	if maxLen < minLen || maxLen > maxLenLimit {
+		return fmt.Errorf("%w: got %d", ErrInvalidMaxLen, maxLen)
+	}
+	if maxTweakLen > maxTweakSize {
+		return fmt.Errorf("%w: got %d", ErrInvalidMaxTweakLen, maxTweakLen)
+	}
+	return nil
+}
+
+// validateInput - Checks the tweak length, the numeral string length and every numeral
+func validateInput(radix, minLen, maxLen, maxTweakLen uint64, tweak []byte, X []uint16) error {
+	n := uint64(len(X))
+	if n < minLen || n > maxLen {
+		return fmt.Errorf("%w: %d is outside [%d, %d]", ErrInputLength, n, minLen, maxLen)
+	}
+	if uint64(len(tweak)) > maxTweakLen {
+		return fmt.Errorf("%w: %d > %d", ErrTweakLength, len(tweak), maxTweakLen)
+	}
+	for i, x := range X {
+		if uint64(x) >= radix {
+			return fmt.Errorf("%w: X[%d] = %d, radix %d", ErrNumeralOutOfRange, i, x, radix)
+		}
+	}
+	return nil
+}
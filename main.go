@@ -1,18 +1,35 @@
-// // main.go
+// main.go
 package main
 
+import (
+	"fmt"
+	"log"
+
+	"github.com/ac999/go-fpe/algorithms"
+)
+
 func main() {
-	// 	K := []byte("examplekey123456") // 16 bytes for AES-128
-	// 	T := []byte("tweak")
-	// 	X := "1234567890"
-	// 	radix := 10
-	// 	minlen := 2
-	// 	maxlen := 32
-	// 	maxTlen := 16
+	K := []byte("examplekey123456") // 16 bytes for AES-128
+	T := []byte("tweak")
+	X := "1234567890"
+	minlen := uint64(6) // 10^6 >= 1,000,000
+	maxlen := uint64(32)
+	maxTlen := uint64(16)
+
+	alphabet, err := algorithms.LookupAlphabet("digits")
+	if err != nil {
+		log.Fatal(err)
+	}
 
-	// 	encrypted := algorithms.FF1Encrypt(K, T, X, radix, minlen, maxlen, maxTlen)
-	// 	fmt.Println("Encrypted:", encrypted)
+	encrypted, err := algorithms.EncryptString(K, T, X, alphabet, minlen, maxlen, maxTlen)
+	if err != nil {
+		log.Fatal(err)
+	}
+	fmt.Println("Encrypted:", encrypted)
 
-	// 	decrypted := algorithms.FF1Decrypt(K, T, encrypted, radix, minlen, maxlen, maxTlen)
-	// 	fmt.Println("Decrypted:", decrypted)
+	decrypted, err := algorithms.DecryptString(K, T, encrypted, alphabet, minlen, maxlen, maxTlen)
+	if err != nil {
+		log.Fatal(err)
+	}
+	fmt.Println("Decrypted:", decrypted)
 }
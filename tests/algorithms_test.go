@@ -3,11 +3,22 @@ package tests
 
 import (
 	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/des"
+	"crypto/rand"
 	"encoding/hex"
+	"errors"
 	"fmt"
 	"math/big"
+	"net/netip"
+	"os"
 	"reflect"
+	"strings"
+	"sync"
 	"testing"
+	"time"
+	"unicode"
 
 	"github.com/ac999/go-fpe/algorithms"
 )
@@ -22,11 +33,11 @@ func TestStringToNumeralSlice(t *testing.T) {
 	tests := []struct {
 		input     string
 		alphabet  string
-		expected  []byte
+		expected  []uint16
 		shouldErr bool
 	}{
-		{"hello", alphabets["base26"], []byte{7, 4, 11, 11, 14}, false},
-		{"01234", alphabets["base10"], []byte{0, 1, 2, 3, 4}, false},
+		{"hello", alphabets["base26"], []uint16{7, 4, 11, 11, 14}, false},
+		{"01234", alphabets["base10"], []uint16{0, 1, 2, 3, 4}, false},
 		{"hello1", alphabets["base26"], nil, true}, // Invalid character '1' for base26
 	}
 
@@ -597,14 +608,14 @@ func TestSTRmRadix(t *testing.T) {
 		x        uint64
 		radix    uint64
 		m        int64
-		expected []byte
+		expected []uint16
 	}{
-		{559, 12, 4, []byte{0, 3, 10, 7}},
-		{1, 2, 8, []byte{0, 0, 0, 0, 0, 0, 0, 1}},
-		{255, 16, 2, []byte{15, 15}},
-		{1024, 2, 11, []byte{1, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0}},
-		{123, 10, 3, []byte{1, 2, 3}},
-		{0, 256, 10, []byte{0, 0, 0, 0, 0, 0, 0, 0, 0, 0}},
+		{559, 12, 4, []uint16{0, 3, 10, 7}},
+		{1, 2, 8, []uint16{0, 0, 0, 0, 0, 0, 0, 1}},
+		{255, 16, 2, []uint16{15, 15}},
+		{1024, 2, 11, []uint16{1, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0}},
+		{123, 10, 3, []uint16{1, 2, 3}},
+		{0, 256, 10, []uint16{0, 0, 0, 0, 0, 0, 0, 0, 0, 0}},
 	}
 
 	for _, test := range tests {
@@ -626,43 +637,43 @@ func TestBigSTRmRadix(t *testing.T) {
 		x        *big.Int
 		radix    uint64
 		m        int64
-		expected []byte
+		expected []uint16
 	}{
 		{
 			x:        big.NewInt(559),
 			radix:    12,
 			m:        4,
-			expected: []byte{0, 3, 10, 7},
+			expected: []uint16{0, 3, 10, 7},
 		},
 		{
 			x:        big.NewInt(1),
 			radix:    2,
 			m:        8,
-			expected: []byte{0, 0, 0, 0, 0, 0, 0, 1},
+			expected: []uint16{0, 0, 0, 0, 0, 0, 0, 1},
 		},
 		{
 			x:        big.NewInt(255),
 			radix:    16,
 			m:        2,
-			expected: []byte{15, 15},
+			expected: []uint16{15, 15},
 		},
 		{
 			x:        big.NewInt(1024),
 			radix:    2,
 			m:        11,
-			expected: []byte{1, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0},
+			expected: []uint16{1, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0},
 		},
 		{
 			x:        big.NewInt(123),
 			radix:    10,
 			m:        3,
-			expected: []byte{1, 2, 3},
+			expected: []uint16{1, 2, 3},
 		},
 		{
 			x:        big.NewInt(0),
 			radix:    256,
 			m:        10,
-			expected: []byte{0, 0, 0, 0, 0, 0, 0, 0, 0, 0},
+			expected: []uint16{0, 0, 0, 0, 0, 0, 0, 0, 0, 0},
 		},
 	}
 
@@ -708,6 +719,18 @@ func TestAesEncrypt(t *testing.T) {
 			plaintextHex:      "202122232425262728292A2B2C2D2E2F",
 			expectedCipherHex: "D31DD57E62812CDDABD1CCAA3C47979B",
 		},
+		{
+			name:              "FIPS 197 Appendix C.2: AES-192",
+			keyHex:            "000102030405060708090A0B0C0D0E0F1011121314151617",
+			plaintextHex:      "00112233445566778899AABBCCDDEEFF",
+			expectedCipherHex: "DDA97CA4864CDFE06EAF70A0EC0D7191",
+		},
+		{
+			name:              "FIPS 197 Appendix C.3: AES-256",
+			keyHex:            "000102030405060708090A0B0C0D0E0F101112131415161718191A1B1C1D1E1F",
+			plaintextHex:      "00112233445566778899AABBCCDDEEFF",
+			expectedCipherHex: "8EA2B7CA516745BFEAFC49904B496089",
+		},
 	}
 
 	for _, test := range tests {
@@ -736,6 +759,76 @@ func TestAesEncrypt(t *testing.T) {
 	}
 }
 
+// TestAesEncryptKeySizes compares AesEncrypt with crypto/aes for every key size.
+func TestAesEncryptKeySizes(t *testing.T) {
+	for _, keySize := range []int{16, 24, 32} {
+		t.Run(fmt.Sprintf("AES-%d", keySize*8), func(t *testing.T) {
+			key := make([]byte, keySize)
+			plaintext := make([]byte, aes.BlockSize)
+			for i := 0; i < 16; i++ {
+				rand.Read(key)
+				rand.Read(plaintext)
+
+				block, _ := aes.NewCipher(key)
+				expected := make([]byte, aes.BlockSize)
+				block.Encrypt(expected, plaintext)
+
+				if got := algorithms.AesEncrypt(plaintext, key); !bytes.Equal(got, expected) {
+					t.Errorf("AesEncrypt(%X, %X) = %X, expected %X", plaintext, key, got, expected)
+				}
+			}
+		})
+	}
+}
+
+// TestAesDecrypt validates the AES inverse cipher against the FIPS 197 Appendix C vectors.
+func TestAesDecrypt(t *testing.T) {
+	tests := []struct {
+		name         string
+		keyHex       string
+		plaintextHex string
+		cipherHex    string
+	}{
+		{
+			name:         "FIPS 197 Appendix C.1: AES-128",
+			keyHex:       "000102030405060708090A0B0C0D0E0F",
+			plaintextHex: "00112233445566778899AABBCCDDEEFF",
+			cipherHex:    "69C4E0D86A7B0430D8CDB78070B4C55A",
+		},
+		{
+			name:         "FIPS 197 Appendix C.2: AES-192",
+			keyHex:       "000102030405060708090A0B0C0D0E0F1011121314151617",
+			plaintextHex: "00112233445566778899AABBCCDDEEFF",
+			cipherHex:    "DDA97CA4864CDFE06EAF70A0EC0D7191",
+		},
+		{
+			name:         "FIPS 197 Appendix C.3: AES-256",
+			keyHex:       "000102030405060708090A0B0C0D0E0F101112131415161718191A1B1C1D1E1F",
+			plaintextHex: "00112233445566778899AABBCCDDEEFF",
+			cipherHex:    "8EA2B7CA516745BFEAFC49904B496089",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			key, _ := hex.DecodeString(test.keyHex)
+			plaintext, _ := hex.DecodeString(test.plaintextHex)
+			cipher, _ := hex.DecodeString(test.cipherHex)
+
+			decrypted := algorithms.AesDecrypt(cipher, key)
+			if !bytes.Equal(decrypted, plaintext) {
+				t.Errorf("AesDecrypt() = %X, expected %X", decrypted, plaintext)
+			}
+
+			// Round trip through the forward cipher
+			roundTrip := algorithms.AesDecrypt(algorithms.AesEncrypt(plaintext, key), key)
+			if !bytes.Equal(roundTrip, plaintext) {
+				t.Errorf("AesDecrypt(AesEncrypt()) = %X, expected %X", roundTrip, plaintext)
+			}
+		})
+	}
+}
+
 func TestPRF(t *testing.T) {
 	K := []byte{0x2b, 0x7e, 0x15, 0x16, 0x28, 0xae, 0xd2, 0xa6, 0xab, 0xf7, 0x15, 0x88, 0x09, 0xcf, 0x4f, 0x3c}
 	X := []byte{1, 2, 1, 0, 0, 10, 10, 5, 0, 0, 0, 10, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 221, 213}
@@ -902,3 +995,1994 @@ func TestFF1EncryptDecrypt(t *testing.T) {
 		})
 	}
 }
+
+func TestFF1Reusable(t *testing.T) {
+	// Sample vectors from FF1samples.pdf, all under the same key
+	key, _ := hex.DecodeString("2B7E151628AED2A6ABF7158809CF4F3C")
+	testCases := []struct {
+		tweak        []byte
+		plaintextStr string
+		expectedEnc  string
+	}{
+		{[]byte{}, "0123456789", "2433477484"},
+		{[]byte{57, 56, 55, 54, 53, 52, 51, 50, 49, 48}, "0123456789", "6124200773"},
+	}
+
+	ff1, err := algorithms.NewFF1(key, 10, 6, 32, 16)
+	if err != nil {
+		t.Fatalf("NewFF1() error: %v", err)
+	}
+
+	// Run every vector from several goroutines at once to exercise the shared state
+	errs := make(chan error, 8*len(testCases))
+	var wg sync.WaitGroup
+	for g := 0; g < 8; g++ {
+		for _, tc := range testCases {
+			wg.Add(1)
+			go func(tweak []byte, pt, ct string) {
+				defer wg.Done()
+				plaintext, _ := algorithms.StringToNumeralSlice(pt, alphabets["base10"])
+				expected, _ := algorithms.StringToNumeralSlice(ct, alphabets["base10"])
+
+				ciphertext, err := ff1.Encrypt(tweak, plaintext)
+				if err != nil {
+					errs <- err
+					return
+				}
+				if !reflect.DeepEqual(ciphertext, expected) {
+					errs <- fmt.Errorf("Encrypt(%s) = %v, expected %v", pt, ciphertext, expected)
+					return
+				}
+				decrypted, err := ff1.Decrypt(tweak, ciphertext)
+				if err != nil {
+					errs <- err
+					return
+				}
+				if !reflect.DeepEqual(decrypted, plaintext) {
+					errs <- fmt.Errorf("Decrypt(%s) = %v, expected %v", ct, decrypted, plaintext)
+				}
+			}(tc.tweak, tc.plaintextStr, tc.expectedEnc)
+		}
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		t.Error(err)
+	}
+
+	// Lengths outside [minLen, maxLen] are rejected
+	if _, err := ff1.Encrypt(nil, make([]uint16, 33)); err == nil {
+		t.Errorf("expected error for input longer than maxLen")
+	}
+}
+
+func TestFF1Validation(t *testing.T) {
+	key, _ := hex.DecodeString("2B7E151628AED2A6ABF7158809CF4F3C")
+
+	paramTests := []struct {
+		name                               string
+		radix, minLen, maxLen, maxTweakLen uint64
+		expected                           error
+	}{
+		{"Valid radix 10", 10, 6, 32, 16, nil},
+		{"Valid radix 2^16", 65536, 2, 32, 16, nil},
+		{"Radix 1", 1, 6, 32, 16, algorithms.ErrInvalidRadix},
+		{"Radix above 2^16", 65537, 2, 32, 16, algorithms.ErrInvalidRadix},
+		{"minlen below 2", 65536, 1, 32, 16, algorithms.ErrInvalidMinLen},
+		{"Domain too small", 10, 5, 32, 16, algorithms.ErrDomainTooSmall},
+		{"maxlen below minlen", 10, 6, 5, 16, algorithms.ErrInvalidMaxLen},
+		{"maxlen above 2^32-1", 10, 6, 1 << 32, 16, algorithms.ErrInvalidMaxLen},
+		{"maxTlen above 2^32-1", 10, 6, 32, 1 << 32, algorithms.ErrInvalidMaxTweakLen},
+	}
+
+	for _, tt := range paramTests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := algorithms.NewFF1(key, tt.radix, tt.minLen, tt.maxLen, tt.maxTweakLen)
+			if !errors.Is(err, tt.expected) {
+				t.Errorf("NewFF1() error = %v, expected %v", err, tt.expected)
+			}
+		})
+	}
+
+	ff1, err := algorithms.NewFF1(key, 10, 6, 12, 4)
+	if err != nil {
+		t.Fatalf("NewFF1() error: %v", err)
+	}
+
+	inputTests := []struct {
+		name     string
+		tweak    []byte
+		X        []uint16
+		expected error
+	}{
+		{"Empty input", nil, []uint16{}, algorithms.ErrInputLength},
+		{"Input too short", nil, []uint16{1, 2, 3, 4, 5}, algorithms.ErrInputLength},
+		{"Input too long", nil, make([]uint16, 13), algorithms.ErrInputLength},
+		{"Tweak too long", []byte{1, 2, 3, 4, 5}, make([]uint16, 6), algorithms.ErrTweakLength},
+		{"Numeral equal to radix", nil, []uint16{0, 1, 2, 3, 4, 10}, algorithms.ErrNumeralOutOfRange},
+	}
+
+	for _, tt := range inputTests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := ff1.Encrypt(tt.tweak, tt.X); !errors.Is(err, tt.expected) {
+				t.Errorf("Encrypt() error = %v, expected %v", err, tt.expected)
+			}
+			if _, err := ff1.Decrypt(tt.tweak, tt.X); !errors.Is(err, tt.expected) {
+				t.Errorf("Decrypt() error = %v, expected %v", err, tt.expected)
+			}
+		})
+	}
+
+	// The one-shot functions apply the same checks
+	if _, err := algorithms.Encrypt(key, nil, []uint16{}, 10); !errors.Is(err, algorithms.ErrInputLength) {
+		t.Errorf("Encrypt() error = %v, expected %v", err, algorithms.ErrInputLength)
+	}
+	if _, err := algorithms.Decrypt(key, nil, []uint16{1, 2, 3, 4, 5, 6}, 1<<17); !errors.Is(err, algorithms.ErrInvalidRadix) {
+		t.Errorf("Decrypt() error = %v, expected %v", err, algorithms.ErrInvalidRadix)
+	}
+}
+
+func TestMinLen(t *testing.T) {
+	tests := []struct {
+		radix    uint64
+		expected uint64
+	}{
+		{2, 20},
+		{10, 6},
+		{26, 5},
+		{36, 4},
+		{1000, 2},
+		{65536, 2},
+	}
+
+	for _, tt := range tests {
+		if got := algorithms.MinLen(tt.radix); got != tt.expected {
+			t.Errorf("MinLen(%d) = %d, expected %d", tt.radix, got, tt.expected)
+		}
+	}
+}
+
+// recordingTracer keeps every event it receives
+type recordingTracer struct {
+	events []algorithms.TraceEvent
+}
+
+func (r *recordingTracer) Trace(ev algorithms.TraceEvent) {
+	r.events = append(r.events, ev)
+}
+
+func TestFF1Tracer(t *testing.T) {
+	key, _ := hex.DecodeString("2B7E151628AED2A6ABF7158809CF4F3C")
+	X := []uint16{0, 1, 2, 3, 4, 5, 6, 7, 8, 9}
+
+	recorder := &recordingTracer{}
+	ff1, err := algorithms.NewFF1(key, 10, 6, 32, 16, algorithms.WithTracer(recorder))
+	if err != nil {
+		t.Fatalf("NewFF1() error: %v", err)
+	}
+	if _, err := ff1.Encrypt(nil, X); err != nil {
+		t.Fatalf("Encrypt() error: %v", err)
+	}
+
+	// Every round reports Q, R, S, y and c
+	counts := map[string]int{}
+	for _, ev := range recorder.events {
+		if ev.Op != "FF1.Encrypt" {
+			t.Errorf("unexpected Op %q", ev.Op)
+		}
+		counts[ev.Name]++
+		if ev.Name == "u" && ev.Value.(uint64) != 5 {
+			t.Errorf("u = %v, expected 5", ev.Value)
+		}
+	}
+	for _, name := range []string{"Q", "R", "S", "y", "c"} {
+		if counts[name] != 10 {
+			t.Errorf("%s traced %d times, expected 10", name, counts[name])
+		}
+	}
+	for _, name := range []string{"u", "v", "b", "d", "P"} {
+		if counts[name] != 1 {
+			t.Errorf("%s traced %d times, expected 1", name, counts[name])
+		}
+	}
+
+	// The text tracer reproduces the dump the library used to print, key left out, for the
+	// second NIST sample: testdata/ff1_trace.golden is that output of Encrypt, then Decrypt
+	var out bytes.Buffer
+	tweak, _ := hex.DecodeString("39383736353433323130")
+	ff1, _ = algorithms.NewFF1(key, 10, 6, 32, 16, algorithms.WithTracer(algorithms.NewTextTracer(&out)))
+	Y, err := ff1.Encrypt(tweak, X)
+	if err != nil {
+		t.Fatalf("Encrypt() error: %v", err)
+	}
+	if _, err := ff1.Decrypt(tweak, Y); err != nil {
+		t.Fatalf("Decrypt() error: %v", err)
+	}
+	golden, err := os.ReadFile("testdata/ff1_trace.golden")
+	if err != nil {
+		t.Fatalf("reading golden transcript: %v", err)
+	}
+	if got, want := strings.Split(out.String(), "\n"), strings.Split(string(golden), "\n"); out.String() != string(golden) {
+		for i := 0; i < len(want); i++ {
+			if i >= len(got) {
+				t.Fatalf("text trace stops before golden line %d %q", i+1, want[i])
+			}
+			if got[i] != want[i] {
+				t.Fatalf("text trace line %d = %q, expected %q", i+1, got[i], want[i])
+			}
+		}
+		t.Fatalf("text trace continues after the golden transcript with %q", got[len(want)])
+	}
+
+	// Inputs longer than one block of S also get the lines of every block j
+	out.Reset()
+	ff1, _ = algorithms.NewFF1(key, 10, 6, 64, 16, algorithms.WithTracer(algorithms.NewTextTracer(&out)))
+	if _, err := ff1.Encrypt(nil, make([]uint16, 60)); err != nil {
+		t.Fatalf("Encrypt() error: %v", err)
+	}
+	for _, line := range []string{"Step 6.iii iteration 1 Step 1: RxorJ is ", "Step 6.iii iteration 1 Step 4: S after append is "} {
+		if strings.Count(out.String(), line) != 10 {
+			t.Errorf("text trace has %d lines starting with %q, expected 10", strings.Count(out.String(), line), line)
+		}
+	}
+
+	// A nil tracer turns tracing off
+	ff1, err = algorithms.NewFF1(key, 10, 6, 32, 16, algorithms.WithTracer(nil))
+	if err != nil {
+		t.Fatalf("NewFF1() error: %v", err)
+	}
+	if _, err := ff1.Encrypt(nil, X); err != nil {
+		t.Errorf("Encrypt() with a nil tracer error: %v", err)
+	}
+}
+
+func TestFF1Uint64Path(t *testing.T) {
+	key, _ := hex.DecodeString("2B7E151628AED2A6ABF7158809CF4F3C")
+
+	// Untraced calls on short inputs skip big.Int; a traced cipher always takes the big.Int
+	// path, so both must agree on every length around the 2^63 cut-off and on tweaks that
+	// put the round data in different blocks of P || Q
+	tweaks := [][]byte{nil, []byte("tweak"), bytes.Repeat([]byte{0xa5}, 11), bytes.Repeat([]byte{0x5a}, 27)}
+	for _, radix := range []uint64{2, 10, 36, 256, 65536} {
+		fast, _ := algorithms.NewFF1(key, radix, algorithms.MinLen(radix), 70, 32)
+		traced, _ := algorithms.NewFF1(key, radix, algorithms.MinLen(radix), 70, 32, algorithms.WithTracer(&recordingTracer{}))
+		for n := algorithms.MinLen(radix); n <= 70; n++ {
+			X := make([]uint16, n)
+			for i := range X {
+				X[i] = uint16((uint64(i)*7919 + n) % radix)
+			}
+			for _, tweak := range tweaks {
+				want, err := traced.Encrypt(tweak, X)
+				if err != nil {
+					t.Fatalf("radix %d, length %d: Encrypt() error: %v", radix, n, err)
+				}
+				if got, _ := fast.Encrypt(tweak, X); !reflect.DeepEqual(got, want) {
+					t.Errorf("radix %d, length %d, tweak %x: Encrypt() = %v, big.Int path %v", radix, n, tweak, got, want)
+				}
+				if got, _ := traced.Decrypt(tweak, want); !reflect.DeepEqual(got, X) {
+					t.Errorf("radix %d, length %d, tweak %x: big.Int Decrypt() = %v, expected %v", radix, n, tweak, got, X)
+				}
+				if got, _ := fast.Decrypt(tweak, want); !reflect.DeepEqual(got, X) {
+					t.Errorf("radix %d, length %d, tweak %x: Decrypt() = %v, expected %v", radix, n, tweak, got, X)
+				}
+			}
+		}
+	}
+}
+
+func TestFF1WideRadix(t *testing.T) {
+	key, _ := hex.DecodeString("2B7E151628AED2A6ABF7158809CF4F3C")
+	tests := []struct {
+		name  string
+		radix uint64
+		X     []uint16
+	}{
+		{"Radix 256", 256, []uint16{0, 1, 127, 128, 254, 255, 17, 42}},
+		{"Radix 257", 257, []uint16{256, 0, 1, 255, 256, 128}},
+		{"Radix 65536", 65536, []uint16{0, 0xFFFF, 0x0410, 0x03A9, 0x00E9, 0x8000, 1}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ff1, err := algorithms.NewFF1(key, tt.radix, algorithms.MinLen(tt.radix), 64, 16)
+			if err != nil {
+				t.Fatalf("NewFF1() error: %v", err)
+			}
+			tweak := []byte("wide")
+			ciphertext, err := ff1.Encrypt(tweak, tt.X)
+			if err != nil {
+				t.Fatalf("Encrypt() error: %v", err)
+			}
+			if reflect.DeepEqual(ciphertext, tt.X) {
+				t.Errorf("Encrypt() returned the plaintext")
+			}
+			for i, c := range ciphertext {
+				if uint64(c) >= tt.radix {
+					t.Errorf("ciphertext[%d] = %d is not below radix %d", i, c, tt.radix)
+				}
+			}
+			decrypted, err := ff1.Decrypt(tweak, ciphertext)
+			if err != nil {
+				t.Fatalf("Decrypt() error: %v", err)
+			}
+			if !reflect.DeepEqual(decrypted, tt.X) {
+				t.Errorf("Decrypt() = %v, expected %v", decrypted, tt.X)
+			}
+		})
+	}
+}
+
+func TestBigSTRmBytes(t *testing.T) {
+	tests := []struct {
+		x        *big.Int
+		s        int64
+		expected []byte
+	}{
+		{big.NewInt(10), 1, []byte{10}},
+		{big.NewInt(65536), 3, []byte{1, 0, 0}},
+		{big.NewInt(0x0102), 4, []byte{0, 0, 1, 2}},
+		{big.NewInt(0), 0, []byte{}},
+	}
+
+	for _, tt := range tests {
+		if got := algorithms.BigSTRmBytes(tt.x, tt.s); !bytes.Equal(got, tt.expected) {
+			t.Errorf("BigSTRmBytes(%v, %d) = %v, expected %v", tt.x, tt.s, got, tt.expected)
+		}
+	}
+}
+
+func TestFF31EncryptDecrypt(t *testing.T) {
+	// FF3-1 sample vectors (56-bit tweaks)
+	testCases := []struct {
+		name         string
+		keyHex       string
+		tweakHex     string
+		plaintextStr string
+		expectedEnc  string
+		radix        uint64
+	}{
+		{
+			name:         "FF3-1-AES128-Sample1",
+			keyHex:       "2DE79D232DF5585D68CE47882AE256D6",
+			tweakHex:     "CBD09280979564",
+			plaintextStr: "3992520240",
+			expectedEnc:  "8901801106",
+			radix:        10,
+		},
+		{
+			name:         "FF3-1-AES128-Sample2",
+			keyHex:       "01C63017111438F7FC8E24EB16C71AB5",
+			tweakHex:     "C4E822DCD09F27",
+			plaintextStr: "60761757463116869318437658042297305934914824457484538562",
+			expectedEnc:  "35637144092473838892796702739628394376915177448290847293",
+			radix:        10,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			// Step 1: Parse the key and the tweak
+			key, err := hex.DecodeString(tc.keyHex)
+			if err != nil {
+				t.Fatalf("Failed to decode key: %v", err)
+			}
+			tweak, err := hex.DecodeString(tc.tweakHex)
+			if err != nil {
+				t.Fatalf("Failed to decode tweak: %v", err)
+			}
+
+			// Step 2: Convert plaintext and expected ciphertext to numeral slices
+			plaintext, err := algorithms.StringToNumeralSlice(tc.plaintextStr, alphabets["base10"])
+			if err != nil {
+				t.Fatalf("Failed to convert plaintext string to numeral slice: %v", err)
+			}
+			expectedEnc, err := algorithms.StringToNumeralSlice(tc.expectedEnc, alphabets["base10"])
+			if err != nil {
+				t.Fatalf("Failed to convert expected ciphertext string to numeral slice: %v", err)
+			}
+
+			// Step 3: Perform encryption
+			ciphertext, err := algorithms.FF31Encrypt(key, tweak, plaintext, tc.radix)
+			if err != nil {
+				t.Fatalf("Encryption failed: %v", err)
+			}
+
+			// Step 4: Validate encryption result
+			if !reflect.DeepEqual(ciphertext, expectedEnc) {
+				t.Errorf("FF31Encrypt() result = %v, expected %v", ciphertext, expectedEnc)
+			}
+
+			// Step 5: Perform decryption
+			decryptedText, err := algorithms.FF31Decrypt(key, tweak, ciphertext, tc.radix)
+			if err != nil {
+				t.Fatalf("Decryption failed: %v", err)
+			}
+
+			// Step 6: Validate decryption result
+			if !reflect.DeepEqual(decryptedText, plaintext) {
+				t.Errorf("FF31Decrypt() result = %v, expected %v", decryptedText, plaintext)
+			}
+		})
+	}
+
+	key, _ := hex.DecodeString("2DE79D232DF5585D68CE47882AE256D6")
+
+	// The tweak is exactly 56 bits
+	if _, err := algorithms.FF31Encrypt(key, make([]byte, 8), make([]uint16, 10), 10); !errors.Is(err, algorithms.ErrInvalidTweak) {
+		t.Errorf("FF31Encrypt() error = %v, expected %v", err, algorithms.ErrInvalidTweak)
+	}
+
+	// maxlen is bounded by 2 * floor(log_radix(2^96))
+	if got := algorithms.FF3MaxLen(10); got != 56 {
+		t.Errorf("FF3MaxLen(10) = %d, expected 56", got)
+	}
+	if _, err := algorithms.NewFF31(key, 10, 6, 57); !errors.Is(err, algorithms.ErrInvalidMaxLen) {
+		t.Errorf("NewFF31() error = %v, expected %v", err, algorithms.ErrInvalidMaxLen)
+	}
+}
+
+func TestLegacyFF3Decrypt(t *testing.T) {
+	// Test cases from FF3samples.pdf
+	testCases := []struct {
+		name          string
+		keyHex        string
+		tweakHex      string
+		ciphertextStr string
+		expectedDec   string
+		alphabet      string
+	}{
+		{
+			name:          "FF3-AES128-Sample1",
+			keyHex:        "EF4359D8D580AA4F7F036D6F04FC6A94",
+			tweakHex:      "D8E7920AFA330A73",
+			ciphertextStr: "750918814058654607",
+			expectedDec:   "890121234567890000",
+			alphabet:      alphabets["base10"],
+		},
+		{
+			name:          "FF3-AES128-Sample2",
+			keyHex:        "EF4359D8D580AA4F7F036D6F04FC6A94",
+			tweakHex:      "9A768A92F60E12D8",
+			ciphertextStr: "018989839189395384",
+			expectedDec:   "890121234567890000",
+			alphabet:      alphabets["base10"],
+		},
+		{
+			name:          "FF3-AES128-Sample4",
+			keyHex:        "EF4359D8D580AA4F7F036D6F04FC6A94",
+			tweakHex:      "0000000000000000",
+			ciphertextStr: "34695224821734535122613701434",
+			expectedDec:   "89012123456789000000789000000",
+			alphabet:      alphabets["base10"],
+		},
+		{
+			name:          "FF3-AES128-Sample5",
+			keyHex:        "EF4359D8D580AA4F7F036D6F04FC6A94",
+			tweakHex:      "9A768A92F60E12D8",
+			ciphertextStr: "g2pk40i992fn20cjakb",
+			expectedDec:   "0123456789abcdefghi",
+			alphabet:      "0123456789abcdefghijklmnop",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			key, _ := hex.DecodeString(tc.keyHex)
+			tweak, _ := hex.DecodeString(tc.tweakHex)
+			radix := uint64(len(tc.alphabet))
+
+			ff3, err := algorithms.NewLegacyFF3(key, radix, algorithms.MinLen(radix), algorithms.FF3MaxLen(radix))
+			if err != nil {
+				t.Fatalf("NewLegacyFF3() error: %v", err)
+			}
+			ciphertext, err := algorithms.StringToNumeralSlice(tc.ciphertextStr, tc.alphabet)
+			if err != nil {
+				t.Fatalf("Failed to convert ciphertext string to numeral slice: %v", err)
+			}
+			decrypted, err := ff3.Decrypt(tweak, ciphertext)
+			if err != nil {
+				t.Fatalf("Decrypt() error: %v", err)
+			}
+			decryptedStr, _ := algorithms.NumeralSliceToString(decrypted, tc.alphabet)
+			if decryptedStr != tc.expectedDec {
+				t.Errorf("Decrypt() = %s, expected %s", decryptedStr, tc.expectedDec)
+			}
+		})
+	}
+}
+
+func TestMigrateFF3ToFF1(t *testing.T) {
+	ff3Key, _ := hex.DecodeString("EF4359D8D580AA4F7F036D6F04FC6A94")
+	ff3Tweak, _ := hex.DecodeString("D8E7920AFA330A73")
+	ff1Key, _ := hex.DecodeString("2B7E151628AED2A6ABF7158809CF4F3C")
+
+	ff3, _ := algorithms.NewLegacyFF3(ff3Key, 10, 6, 56)
+	ff1, _ := algorithms.NewFF1(ff1Key, 10, 6, 56, 16)
+
+	archived, _ := algorithms.StringToNumeralSlice("750918814058654607", alphabets["base10"])
+	plaintext, _ := algorithms.StringToNumeralSlice("890121234567890000", alphabets["base10"])
+
+	migrated, err := algorithms.MigrateFF3ToFF1(ff3, ff3Tweak, ff1, nil, archived)
+	if err != nil {
+		t.Fatalf("MigrateFF3ToFF1() error: %v", err)
+	}
+	expected, _ := ff1.Encrypt(nil, plaintext)
+	if !reflect.DeepEqual(migrated, expected) {
+		t.Errorf("MigrateFF3ToFF1() = %v, expected %v", migrated, expected)
+	}
+
+	// Both ciphers must share the radix
+	ff1Radix36, _ := algorithms.NewFF1(ff1Key, 36, 4, 56, 16)
+	if _, err := algorithms.MigrateFF3ToFF1(ff3, ff3Tweak, ff1Radix36, nil, archived); !errors.Is(err, algorithms.ErrInvalidRadix) {
+		t.Errorf("MigrateFF3ToFF1() error = %v, expected %v", err, algorithms.ErrInvalidRadix)
+	}
+}
+
+func TestNewAES(t *testing.T) {
+	for _, keySize := range []int{16, 24, 32} {
+		key := make([]byte, keySize)
+		rand.Read(key)
+		plaintext := make([]byte, aes.BlockSize)
+		rand.Read(plaintext)
+
+		block, err := algorithms.NewAES(key)
+		if err != nil {
+			t.Fatalf("NewAES() error: %v", err)
+		}
+		reference, _ := aes.NewCipher(key)
+
+		got := make([]byte, aes.BlockSize)
+		expected := make([]byte, aes.BlockSize)
+		block.Encrypt(got, plaintext)
+		reference.Encrypt(expected, plaintext)
+		if !bytes.Equal(got, expected) {
+			t.Errorf("AES-%d Encrypt() = %X, expected %X", keySize*8, got, expected)
+		}
+
+		block.Decrypt(got, got)
+		if !bytes.Equal(got, plaintext) {
+			t.Errorf("AES-%d Decrypt() = %X, expected %X", keySize*8, got, plaintext)
+		}
+	}
+
+	if _, err := algorithms.NewAES(make([]byte, 20)); err == nil {
+		t.Errorf("expected error for a 20 byte key")
+	}
+}
+
+func TestFF1BlockCipherBackends(t *testing.T) {
+	backends := map[string]func(key []byte) (cipher.Block, error){
+		"crypto/aes":     aes.NewCipher,
+		"algorithms.AES": algorithms.NewAES,
+	}
+
+	// Sample vectors from FF1samples.pdf
+	testCases := []struct {
+		keyHex       string
+		tweak        []byte
+		plaintextStr string
+		expectedEnc  string
+		radix        uint64
+	}{
+		{"2B7E151628AED2A6ABF7158809CF4F3C", []byte{}, "0123456789", "2433477484", 10},
+		{"2B7E151628AED2A6ABF7158809CF4F3CEF4359D8D580AA4F", []byte{55, 55, 55, 55, 112, 113, 114, 115, 55, 55, 55}, "0123456789abcdefghi", "xbj3kv35jrawxv32ysr", 36},
+	}
+
+	for name, newBlock := range backends {
+		t.Run(name, func(t *testing.T) {
+			for _, tc := range testCases {
+				key, _ := hex.DecodeString(tc.keyHex)
+				alphabet := alphabets["base10"]
+				if tc.radix == 36 {
+					alphabet = alphabets["base36"]
+				}
+
+				ff1, err := algorithms.NewFF1(key, tc.radix, algorithms.MinLen(tc.radix), 32, 16, algorithms.WithBlockCipher(newBlock))
+				if err != nil {
+					t.Fatalf("NewFF1() error: %v", err)
+				}
+				plaintext, _ := algorithms.StringToNumeralSlice(tc.plaintextStr, alphabet)
+				ciphertext, err := ff1.Encrypt(tc.tweak, plaintext)
+				if err != nil {
+					t.Fatalf("Encrypt() error: %v", err)
+				}
+				ciphertextStr, _ := algorithms.NumeralSliceToString(ciphertext, alphabet)
+				if ciphertextStr != tc.expectedEnc {
+					t.Errorf("Encrypt() = %s, expected %s", ciphertextStr, tc.expectedEnc)
+				}
+				decrypted, _ := ff1.Decrypt(tc.tweak, ciphertext)
+				if !reflect.DeepEqual(decrypted, plaintext) {
+					t.Errorf("Decrypt() = %v, expected %v", decrypted, plaintext)
+				}
+			}
+		})
+	}
+
+	// 64-bit block ciphers are rejected
+	_, err := algorithms.NewFF1(make([]byte, 8), 10, 6, 32, 16, algorithms.WithBlockCipher(des.NewCipher))
+	if !errors.Is(err, algorithms.ErrInvalidBlockSize) {
+		t.Errorf("NewFF1() error = %v, expected %v", err, algorithms.ErrInvalidBlockSize)
+	}
+
+	// So are a nil constructor and one that returns no cipher
+	noBlock := func([]byte) (cipher.Block, error) { return nil, nil }
+	for name, newBlock := range map[string]func([]byte) (cipher.Block, error){"nil": nil, "no block": noBlock} {
+		if _, err := algorithms.NewFF1(make([]byte, 16), 10, 6, 32, 16, algorithms.WithBlockCipher(newBlock)); !errors.Is(err, algorithms.ErrNoBlockCipher) {
+			t.Errorf("NewFF1() with a %s block cipher: error = %v, expected %v", name, err, algorithms.ErrNoBlockCipher)
+		}
+	}
+}
+
+func TestCycleWalkBijection(t *testing.T) {
+	// x -> 5x + 3 mod 64 and its inverse x -> 13(x - 3) mod 64 as a toy permutation of [0, 64)
+	forward := func(x *big.Int) (*big.Int, error) {
+		y := new(big.Int).Mul(x, big.NewInt(5))
+		y.Add(y, big.NewInt(3))
+		return y.Mod(y, big.NewInt(64)), nil
+	}
+	inverse := func(y *big.Int) (*big.Int, error) {
+		x := new(big.Int).Sub(y, big.NewInt(3))
+		x.Mul(x, big.NewInt(13))
+		return x.Mod(x, big.NewInt(64)), nil
+	}
+
+	for _, n := range []int64{2, 3, 10, 33, 63, 64} {
+		t.Run(fmt.Sprintf("N=%d", n), func(t *testing.T) {
+			N := big.NewInt(n)
+			seen := make(map[int64]bool)
+			for x := int64(0); x < n; x++ {
+				y, err := algorithms.CycleWalk(big.NewInt(x), N, 64, forward)
+				if err != nil {
+					t.Fatalf("CycleWalk(%d) error: %v", x, err)
+				}
+				if y.Sign() < 0 || y.Cmp(N) >= 0 {
+					t.Fatalf("CycleWalk(%d) = %v is outside [0, %d)", x, y, n)
+				}
+				if seen[y.Int64()] {
+					t.Fatalf("CycleWalk(%d) = %v collides with an earlier value", x, y)
+				}
+				seen[y.Int64()] = true
+
+				back, err := algorithms.CycleWalk(y, N, 64, inverse)
+				if err != nil || back.Int64() != x {
+					t.Errorf("inverse CycleWalk(%v) = %v, %v, expected %d", y, back, err, x)
+				}
+			}
+		})
+	}
+
+	// The walk gives up after maxWalks steps
+	stuck := func(x *big.Int) (*big.Int, error) { return big.NewInt(100), nil }
+	if _, err := algorithms.CycleWalk(big.NewInt(0), big.NewInt(10), 5, stuck); !errors.Is(err, algorithms.ErrWalkLimit) {
+		t.Errorf("CycleWalk() error = %v, expected %v", err, algorithms.ErrWalkLimit)
+	}
+}
+
+func TestRangeCipher(t *testing.T) {
+	key, _ := hex.DecodeString("2B7E151628AED2A6ABF7158809CF4F3C")
+
+	// Account numbers below 4,200,000,000
+	accounts, err := algorithms.NewRangeCipher(key, big.NewInt(4200000000), 0)
+	if err != nil {
+		t.Fatalf("NewRangeCipher() error: %v", err)
+	}
+	seen := make(map[uint64]bool)
+	for _, x := range []uint64{0, 1, 42, 123456789, 4199999999} {
+		y, err := accounts.EncryptUint64(x)
+		if err != nil {
+			t.Fatalf("EncryptUint64(%d) error: %v", x, err)
+		}
+		if y >= 4200000000 {
+			t.Errorf("EncryptUint64(%d) = %d is outside the range", x, y)
+		}
+		if seen[y] {
+			t.Errorf("EncryptUint64(%d) = %d collides with an earlier value", x, y)
+		}
+		seen[y] = true
+		if back, err := accounts.DecryptUint64(y); err != nil || back != x {
+			t.Errorf("DecryptUint64(%d) = %d, %v, expected %d", y, back, err, x)
+		}
+	}
+	if _, err := accounts.EncryptUint64(4200000000); !errors.Is(err, algorithms.ErrValueOutOfRange) {
+		t.Errorf("EncryptUint64() error = %v, expected %v", err, algorithms.ErrValueOutOfRange)
+	}
+
+	// big.Int domains beyond 2^64, with a tweak
+	N, _ := new(big.Int).SetString("340282366920938463463374607431768211297", 10) // largest prime below 2^128
+	huge, _ := algorithms.NewRangeCipher(key, N, 0)
+	x := new(big.Int).Sub(N, big.NewInt(1))
+	y, err := huge.Encrypt([]byte("tweak"), x)
+	if err != nil {
+		t.Fatalf("Encrypt() error: %v", err)
+	}
+	if back, err := huge.Decrypt([]byte("tweak"), y); err != nil || back.Cmp(x) != 0 {
+		t.Errorf("Decrypt(%v) = %v, %v, expected %v", y, back, err, x)
+	}
+
+	// Small domains are rejected unless asked for
+	if _, err := algorithms.NewRangeCipher(key, big.NewInt(999999), 0); !errors.Is(err, algorithms.ErrDomainTooSmall) {
+		t.Errorf("NewRangeCipher() error = %v, expected %v", err, algorithms.ErrDomainTooSmall)
+	}
+
+	// A tiny range inside the 2^20 minimum domain runs out of walks
+	tiny, _ := algorithms.NewRangeCipher(key, big.NewInt(2), 1, algorithms.WithSmallDomains())
+	failures := 0
+	for x := uint64(0); x < 2; x++ {
+		if _, err := tiny.EncryptUint64(x); errors.Is(err, algorithms.ErrWalkLimit) {
+			failures++
+		}
+	}
+	if failures == 0 {
+		t.Errorf("expected ErrWalkLimit with a walk limit of 1")
+	}
+
+	if _, err := algorithms.NewRangeCipher(key, big.NewInt(1), 0); !errors.Is(err, algorithms.ErrInvalidDomain) {
+		t.Errorf("NewRangeCipher() error = %v, expected %v", err, algorithms.ErrInvalidDomain)
+	}
+}
+
+func TestRangeCipherBijection(t *testing.T) {
+	key, _ := hex.DecodeString("2B7E151628AED2A6ABF7158809CF4F3C")
+
+	// Every value of a small domain, through the real FF1: a permutation that decrypts back.
+	// The walks of all values together cover the 2^20 FF1 inputs once in each direction.
+	const n = 1000
+	rc, err := algorithms.NewRangeCipher(key, big.NewInt(n), 0, algorithms.WithSmallDomains())
+	if err != nil {
+		t.Fatalf("NewRangeCipher() error: %v", err)
+	}
+	seen := make(map[uint64]bool)
+	for x := uint64(0); x < n; x++ {
+		y, err := rc.EncryptUint64(x)
+		if err != nil {
+			t.Fatalf("EncryptUint64(%d) error: %v", x, err)
+		}
+		if y >= n || seen[y] {
+			t.Fatalf("EncryptUint64(%d) = %d is outside [0, %d) or collides with an earlier value", x, y, n)
+		}
+		seen[y] = true
+		if back, err := rc.DecryptUint64(y); err != nil || back != x {
+			t.Errorf("DecryptUint64(%d) = %d, %v, expected %d", y, back, err, x)
+		}
+	}
+}
+
+func TestAlphabet(t *testing.T) {
+	tests := []struct {
+		name     string
+		chars    string
+		input    string
+		expected []uint16
+	}{
+		{"ASCII", alphabets["base26"], "hello", []uint16{7, 4, 11, 11, 14}},
+		{"Cyrillic", "абвгдеёжзийклмнопрстуфхцчшщъыьэюя", "привет", []uint16{16, 17, 9, 2, 5, 19}},
+		{"Greek", "αβγδεζηθικλμνξοπρστυφχψω", "γεια", []uint16{2, 4, 8, 0}},
+		{"Accented Latin", "aàâäbcçdeéèêëf", "çàéf", []uint16{6, 1, 9, 13}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a, err := algorithms.NewAlphabet(tt.chars)
+			if err != nil {
+				t.Fatalf("NewAlphabet() error: %v", err)
+			}
+			if a.Radix() != uint64(len([]rune(tt.chars))) {
+				t.Errorf("Radix() = %d, expected %d", a.Radix(), len([]rune(tt.chars)))
+			}
+			numerals, err := a.Encode(tt.input)
+			if err != nil {
+				t.Fatalf("Encode() error: %v", err)
+			}
+			if !reflect.DeepEqual(numerals, tt.expected) {
+				t.Errorf("Encode(%q) = %v, expected %v", tt.input, numerals, tt.expected)
+			}
+			decoded, err := a.Decode(numerals)
+			if err != nil || decoded != tt.input {
+				t.Errorf("Decode(%v) = %q, %v, expected %q", numerals, decoded, err, tt.input)
+			}
+
+			// The string helpers agree with the Alphabet
+			viaHelper, err := algorithms.StringToNumeralSlice(tt.input, tt.chars)
+			if err != nil || !reflect.DeepEqual(viaHelper, tt.expected) {
+				t.Errorf("StringToNumeralSlice(%q) = %v, %v, expected %v", tt.input, viaHelper, err, tt.expected)
+			}
+			backViaHelper, err := algorithms.NumeralSliceToString(tt.expected, tt.chars)
+			if err != nil || backViaHelper != tt.input {
+				t.Errorf("NumeralSliceToString(%v) = %q, %v, expected %q", tt.expected, backViaHelper, err, tt.input)
+			}
+		})
+	}
+
+	if _, err := algorithms.NewAlphabet("abca"); !errors.Is(err, algorithms.ErrDuplicateCharacter) {
+		t.Errorf("NewAlphabet() error = %v, expected %v", err, algorithms.ErrDuplicateCharacter)
+	}
+	if _, err := algorithms.NewAlphabet("a"); !errors.Is(err, algorithms.ErrInvalidAlphabet) {
+		t.Errorf("NewAlphabet() error = %v, expected %v", err, algorithms.ErrInvalidAlphabet)
+	}
+	greek, _ := algorithms.NewAlphabet("αβγδ")
+	if _, err := greek.Encode("αβz"); !errors.Is(err, algorithms.ErrInvalidCharacter) {
+		t.Errorf("Encode() error = %v, expected %v", err, algorithms.ErrInvalidCharacter)
+	}
+	if _, err := greek.Decode([]uint16{0, 4}); !errors.Is(err, algorithms.ErrNumeralOutOfRange) {
+		t.Errorf("Decode() error = %v, expected %v", err, algorithms.ErrNumeralOutOfRange)
+	}
+}
+
+func TestFF1EncryptString(t *testing.T) {
+	key, _ := hex.DecodeString("2B7E151628AED2A6ABF7158809CF4F3C")
+
+	// Sample 1 from FF1samples.pdf through the string API
+	digits, _ := algorithms.NewAlphabet(alphabets["base10"])
+	ff1, err := algorithms.NewFF1(key, 10, 6, 32, 16, algorithms.WithAlphabet(digits))
+	if err != nil {
+		t.Fatalf("NewFF1() error: %v", err)
+	}
+	if got, err := ff1.EncryptString(nil, "0123456789"); err != nil || got != "2433477484" {
+		t.Errorf("EncryptString() = %q, %v, expected %q", got, err, "2433477484")
+	}
+
+	// A multibyte alphabet keeps every character in the alphabet
+	cyrillic, _ := algorithms.NewAlphabet("абвгдеёжзийклмнопрстуфхцчшщъыьэюя")
+	ff1, err = algorithms.NewFF1(key, cyrillic.Radix(), algorithms.MinLen(cyrillic.Radix()), 32, 16, algorithms.WithAlphabet(cyrillic))
+	if err != nil {
+		t.Fatalf("NewFF1() error: %v", err)
+	}
+	ciphertext, err := ff1.EncryptString([]byte("tweak"), "здравствуйте")
+	if err != nil {
+		t.Fatalf("EncryptString() error: %v", err)
+	}
+	if len([]rune(ciphertext)) != len([]rune("здравствуйте")) {
+		t.Errorf("EncryptString() = %q changed the length", ciphertext)
+	}
+	for _, r := range ciphertext {
+		if !cyrillic.Contains(r) {
+			t.Errorf("EncryptString() = %q contains %q", ciphertext, r)
+		}
+	}
+	if plaintext, err := ff1.DecryptString([]byte("tweak"), ciphertext); err != nil || plaintext != "здравствуйте" {
+		t.Errorf("DecryptString() = %q, %v, expected %q", plaintext, err, "здравствуйте")
+	}
+
+	// The alphabet must match the radix
+	if _, err := algorithms.NewFF1(key, 36, 4, 32, 16, algorithms.WithAlphabet(cyrillic)); !errors.Is(err, algorithms.ErrInvalidRadix) {
+		t.Errorf("NewFF1() error = %v, expected %v", err, algorithms.ErrInvalidRadix)
+	}
+	plain, _ := algorithms.NewFF1(key, 10, 6, 32, 16)
+	if _, err := plain.EncryptString(nil, "0123456789"); !errors.Is(err, algorithms.ErrNoAlphabet) {
+		t.Errorf("EncryptString() error = %v, expected %v", err, algorithms.ErrNoAlphabet)
+	}
+}
+
+func TestLookupAlphabet(t *testing.T) {
+	tests := []struct {
+		name  string
+		radix uint64
+		first rune
+		last  rune
+	}{
+		{"digits", 10, '0', '9'},
+		{"base10", 10, '0', '9'},
+		{"lowercase", 26, 'a', 'z'},
+		{"uppercase", 26, 'A', 'Z'},
+		{"base36", 36, '0', 'z'},
+		{"base62", 62, '0', 'z'},
+		{"hex", 16, '0', 'f'},
+		{"HEX", 16, '0', 'F'},
+		{"base32", 32, 'A', '7'},
+		{"base32-crockford", 32, '0', 'Z'},
+		{"printable", 95, ' ', '~'},
+		{"base64url", 64, 'A', '_'},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a, err := algorithms.LookupAlphabet(tt.name)
+			if err != nil {
+				t.Fatalf("LookupAlphabet() error: %v", err)
+			}
+			chars := []rune(a.String())
+			if a.Radix() != tt.radix || chars[0] != tt.first || chars[len(chars)-1] != tt.last {
+				t.Errorf("%s: radix %d, %q..%q, expected radix %d, %q..%q", tt.name, a.Radix(), chars[0], chars[len(chars)-1], tt.radix, tt.first, tt.last)
+			}
+		})
+	}
+
+	// Crockford's base32 leaves out the ambiguous letters
+	crockford, _ := algorithms.LookupAlphabet("base32-crockford")
+	for _, r := range "ILOU" {
+		if crockford.Contains(r) {
+			t.Errorf("base32-crockford contains %q", r)
+		}
+	}
+
+	// Every registered alphabet works with the FF1 string API
+	key, _ := hex.DecodeString("2B7E151628AED2A6ABF7158809CF4F3C")
+	for _, name := range algorithms.AlphabetNames() {
+		a, _ := algorithms.LookupAlphabet(name)
+		ff1, err := algorithms.NewFF1(key, a.Radix(), algorithms.MinLen(a.Radix()), 32, 16, algorithms.WithAlphabet(a))
+		if err != nil {
+			t.Fatalf("%s: NewFF1() error: %v", name, err)
+		}
+		plaintext := string([]rune(a.String())[:8])
+		ciphertext, err := ff1.EncryptString(nil, plaintext)
+		if err != nil {
+			t.Fatalf("%s: EncryptString() error: %v", name, err)
+		}
+		if back, err := ff1.DecryptString(nil, ciphertext); err != nil || back != plaintext {
+			t.Errorf("%s: DecryptString() = %q, %v, expected %q", name, back, err, plaintext)
+		}
+	}
+
+	if _, err := algorithms.LookupAlphabet("base99"); !errors.Is(err, algorithms.ErrUnknownAlphabet) {
+		t.Errorf("LookupAlphabet() error = %v, expected %v", err, algorithms.ErrUnknownAlphabet)
+	}
+}
+
+func TestEncryptDecryptString(t *testing.T) {
+	// Test cases from FF1samples.pdf, through the one-shot string API
+	testCases := []struct {
+		name         string
+		keyHex       string
+		tweak        []byte
+		plaintextStr string
+		expectedEnc  string
+		alphabet     string
+	}{
+		{"FF1-AES128-Sample1", "2B7E151628AED2A6ABF7158809CF4F3C", []byte{}, "0123456789", "2433477484", "digits"},
+		{"FF1-AES128-Sample2", "2B7E151628AED2A6ABF7158809CF4F3C", []byte{57, 56, 55, 54, 53, 52, 51, 50, 49, 48}, "0123456789", "6124200773", "digits"},
+		{"FF1-AES192-Sample6", "2B7E151628AED2A6ABF7158809CF4F3CEF4359D8D580AA4F", []byte{55, 55, 55, 55, 112, 113, 114, 115, 55, 55, 55}, "0123456789abcdefghi", "xbj3kv35jrawxv32ysr", "base36"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			key, _ := hex.DecodeString(tc.keyHex)
+			alphabet, _ := algorithms.LookupAlphabet(tc.alphabet)
+
+			ciphertext, err := algorithms.EncryptString(key, tc.tweak, tc.plaintextStr, alphabet, algorithms.MinLen(alphabet.Radix()), 32, 16)
+			if err != nil {
+				t.Fatalf("EncryptString() error: %v", err)
+			}
+			if ciphertext != tc.expectedEnc {
+				t.Errorf("EncryptString() = %q, expected %q", ciphertext, tc.expectedEnc)
+			}
+
+			plaintext, err := algorithms.DecryptString(key, tc.tweak, ciphertext, alphabet, algorithms.MinLen(alphabet.Radix()), 32, 16)
+			if err != nil {
+				t.Fatalf("DecryptString() error: %v", err)
+			}
+			if plaintext != tc.plaintextStr {
+				t.Errorf("DecryptString() = %q, expected %q", plaintext, tc.plaintextStr)
+			}
+		})
+	}
+
+	// The limits apply to the string API as well
+	key, _ := hex.DecodeString("2B7E151628AED2A6ABF7158809CF4F3C")
+	digits, _ := algorithms.LookupAlphabet("digits")
+	if _, err := algorithms.EncryptString(key, nil, "12345", digits, 6, 32, 16); !errors.Is(err, algorithms.ErrInputLength) {
+		t.Errorf("EncryptString() error = %v, expected %v", err, algorithms.ErrInputLength)
+	}
+	if _, err := algorithms.EncryptString(key, nil, "12345a", digits, 6, 32, 16); !errors.Is(err, algorithms.ErrInvalidCharacter) {
+		t.Errorf("EncryptString() error = %v, expected %v", err, algorithms.ErrInvalidCharacter)
+	}
+	if _, err := algorithms.EncryptString(key, nil, "123456", nil, 6, 32, 16); !errors.Is(err, algorithms.ErrNoAlphabet) {
+		t.Errorf("EncryptString() with a nil alphabet error = %v, expected %v", err, algorithms.ErrNoAlphabet)
+	}
+	if _, err := algorithms.DecryptString(key, nil, "123456", nil, 6, 32, 16); !errors.Is(err, algorithms.ErrNoAlphabet) {
+		t.Errorf("DecryptString() with a nil alphabet error = %v, expected %v", err, algorithms.ErrNoAlphabet)
+	}
+}
+
+func TestMixedRadix(t *testing.T) {
+	radices := []uint64{26, 26, 10, 10, 10, 10}
+	X := []uint16{1, 25, 9, 0, 4, 2}
+
+	// 1*26*10^4 + 25*10^4 + 9042
+	expected := big.NewInt(1*26*10000 + 25*10000 + 9042)
+	if got := algorithms.BigNUMMixedRadix(X, radices); got.Cmp(expected) != 0 {
+		t.Errorf("BigNUMMixedRadix() = %v, expected %v", got, expected)
+	}
+	if got := algorithms.BigSTRMixedRadix(expected, radices); !reflect.DeepEqual(got, X) {
+		t.Errorf("BigSTRMixedRadix() = %v, expected %v", got, X)
+	}
+	if got := algorithms.MixedRadixDomain(radices); got.Cmp(big.NewInt(6760000)) != 0 {
+		t.Errorf("MixedRadixDomain() = %v, expected 6760000", got)
+	}
+
+	// A single radix matches BigNUMradix
+	uniform := []uint64{10, 10, 10}
+	if got := algorithms.BigNUMMixedRadix([]uint16{1, 2, 3}, uniform); got.Cmp(algorithms.BigNUMradix([]uint16{1, 2, 3}, 10)) != 0 {
+		t.Errorf("BigNUMMixedRadix() = %v, expected 123", got)
+	}
+}
+
+func TestTemplateCipher(t *testing.T) {
+	key, _ := hex.DecodeString("2B7E151628AED2A6ABF7158809CF4F3C")
+
+	tests := []struct {
+		name    string
+		pattern string
+		input   string
+	}{
+		{"Licence plate", "LL-DDDD-LL", "AB-1234-XY"},
+		{"Phone number", "(DDD) DDD-DDDD", "(555) 123-4567"},
+		{"Escaped literals", `\D\L-DDDD`, "DL-0042"},
+		{"Mixed classes", "hhhh.AAAA.ll", "0a9f.Z9Q1.xy"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, err := algorithms.NewTemplateCipher(key, tt.pattern, algorithms.WithSmallDomains())
+			if err != nil {
+				t.Fatalf("NewTemplateCipher() error: %v", err)
+			}
+			template, _ := algorithms.ParseTemplate(tt.pattern)
+
+			ciphertext, err := c.Encrypt([]byte("field"), tt.input)
+			if err != nil {
+				t.Fatalf("Encrypt() error: %v", err)
+			}
+			if _, err := template.Extract(ciphertext); err != nil {
+				t.Errorf("Encrypt() = %q does not match %q: %v", ciphertext, tt.pattern, err)
+			}
+			if ciphertext == tt.input {
+				t.Errorf("Encrypt() returned the plaintext")
+			}
+
+			plaintext, err := c.Decrypt([]byte("field"), ciphertext)
+			if err != nil || plaintext != tt.input {
+				t.Errorf("Decrypt(%q) = %q, %v, expected %q", ciphertext, plaintext, err, tt.input)
+			}
+		})
+	}
+
+	c, _ := algorithms.NewTemplateCipher(key, "LL-DDDD-LL")
+	for _, bad := range []string{"AB1234XY", "ab-1234-XY", "AB_1234-XY"} {
+		if _, err := c.Encrypt(nil, bad); !errors.Is(err, algorithms.ErrFormatMismatch) {
+			t.Errorf("Encrypt(%q) error = %v, expected %v", bad, err, algorithms.ErrFormatMismatch)
+		}
+	}
+
+	if _, err := algorithms.NewTemplateCipher(key, "DD-DD"); !errors.Is(err, algorithms.ErrDomainTooSmall) {
+		t.Errorf("NewTemplateCipher(\"DD-DD\") error = %v, expected %v", err, algorithms.ErrDomainTooSmall)
+	}
+
+	for _, bad := range []string{"--", `DD\`} {
+		if _, err := algorithms.ParseTemplate(bad); !errors.Is(err, algorithms.ErrInvalidTemplate) {
+			t.Errorf("ParseTemplate(%q) error = %v, expected %v", bad, err, algorithms.ErrInvalidTemplate)
+		}
+	}
+}
+
+func TestMixedRadixCipher(t *testing.T) {
+	key, _ := hex.DecodeString("2B7E151628AED2A6ABF7158809CF4F3C")
+
+	// Numerals: letter, letter, digit, digit, digit, letter
+	radices := []uint64{26, 26, 10, 10, 10, 26}
+	c, err := algorithms.NewMixedRadixCipher(key, radices)
+	if err != nil {
+		t.Fatalf("NewMixedRadixCipher() error: %v", err)
+	}
+	X := []uint16{0, 25, 9, 0, 5, 13}
+	Y, err := c.Encrypt([]byte("plate"), X)
+	if err != nil {
+		t.Fatalf("Encrypt() error: %v", err)
+	}
+	for i, y := range Y {
+		if uint64(y) >= radices[i] {
+			t.Errorf("Encrypt()[%d] = %d is not below radix %d", i, y, radices[i])
+		}
+	}
+	if back, err := c.Decrypt([]byte("plate"), Y); err != nil || !reflect.DeepEqual(back, X) {
+		t.Errorf("Decrypt(%v) = %v, %v, expected %v", Y, back, err, X)
+	}
+
+	// Postcode-like strings: letter, letter, digit, digit, letter, letter
+	upper, _ := algorithms.LookupAlphabet("uppercase")
+	digits, _ := algorithms.LookupAlphabet("digits")
+	postcodes, err := algorithms.NewMixedRadixAlphabetCipher(key, []*algorithms.Alphabet{upper, upper, digits, digits, upper, upper})
+	if err != nil {
+		t.Fatalf("NewMixedRadixAlphabetCipher() error: %v", err)
+	}
+	ciphertext, err := postcodes.EncryptString(nil, "SW1A2A")
+	if err == nil {
+		t.Errorf("EncryptString() accepted a letter in a digit position, got %q", ciphertext)
+	}
+	ciphertext, err = postcodes.EncryptString(nil, "SW12AA")
+	if err != nil {
+		t.Fatalf("EncryptString() error: %v", err)
+	}
+	for i, r := range ciphertext {
+		class := upper
+		if i == 2 || i == 3 {
+			class = digits
+		}
+		if !class.Contains(r) {
+			t.Errorf("EncryptString() = %q has %q at position %d", ciphertext, r, i)
+		}
+	}
+	if back, err := postcodes.DecryptString(nil, ciphertext); err != nil || back != "SW12AA" {
+		t.Errorf("DecryptString(%q) = %q, %v, expected %q", ciphertext, back, err, "SW12AA")
+	}
+
+	if _, err := c.Encrypt(nil, []uint16{0, 0, 10, 0, 0, 0}); !errors.Is(err, algorithms.ErrNumeralOutOfRange) {
+		t.Errorf("Encrypt() error = %v, expected %v", err, algorithms.ErrNumeralOutOfRange)
+	}
+	if _, err := c.Encrypt(nil, []uint16{0, 0}); !errors.Is(err, algorithms.ErrInputLength) {
+		t.Errorf("Encrypt() error = %v, expected %v", err, algorithms.ErrInputLength)
+	}
+	if _, err := algorithms.NewMixedRadixCipher(key, []uint64{10, 1}); !errors.Is(err, algorithms.ErrInvalidRadix) {
+		t.Errorf("NewMixedRadixCipher() error = %v, expected %v", err, algorithms.ErrInvalidRadix)
+	}
+	if _, err := algorithms.NewMixedRadixCipher(key, []uint64{26, 10, 10, 10}); !errors.Is(err, algorithms.ErrDomainTooSmall) {
+		t.Errorf("NewMixedRadixCipher() over 26,000 values: error = %v, expected %v", err, algorithms.ErrDomainTooSmall)
+	}
+}
+
+func TestClassPreservingCipher(t *testing.T) {
+	key, _ := hex.DecodeString("2B7E151628AED2A6ABF7158809CF4F3C")
+	c, err := algorithms.NewClassPreservingCipher(key)
+	if err != nil {
+		t.Fatalf("NewClassPreservingCipher() error: %v", err)
+	}
+
+	class := func(r rune) string {
+		switch {
+		case r >= 'A' && r <= 'Z':
+			return "A"
+		case r >= 'a' && r <= 'z':
+			return "a"
+		case r >= '0' && r <= '9':
+			return "9"
+		}
+		return string(r)
+	}
+
+	for _, pt := range []string{"Ab3-xY9", "order#4711/EU", "user_42@Example", "ABC-123-xyz", "---", ""} {
+		ct, err := c.Encrypt([]byte("ids"), pt)
+		if err != nil {
+			t.Fatalf("Encrypt(%q) error: %v", pt, err)
+		}
+		ptRunes, ctRunes := []rune(pt), []rune(ct)
+		if len(ptRunes) != len(ctRunes) {
+			t.Fatalf("Encrypt(%q) = %q, length changed", pt, ct)
+		}
+		for i := range ptRunes {
+			if class(ptRunes[i]) != class(ctRunes[i]) {
+				t.Errorf("Encrypt(%q) = %q, class changed at %d", pt, ct, i)
+			}
+		}
+		if again, _ := c.Encrypt([]byte("ids"), pt); again != ct {
+			t.Errorf("Encrypt(%q) is not deterministic: %q and %q", pt, ct, again)
+		}
+		if back, err := c.Decrypt([]byte("ids"), ct); err != nil || back != pt {
+			t.Errorf("Decrypt(%q) = %q, %v, expected %q", ct, back, err, pt)
+		}
+	}
+
+	// The layout is part of the tweak, so moving a separator changes the letters and digits too
+	a, _ := c.Encrypt(nil, "AB12-CD34")
+	b, _ := c.Encrypt(nil, "AB1-2CD34")
+	if strings.ReplaceAll(a, "-", "") == strings.ReplaceAll(b, "-", "") {
+		t.Errorf("Encrypt() ignored the layout: %q and %q", a, b)
+	}
+
+	// Short identifiers are below the FF1 minimum domain
+	if _, err := c.Encrypt(nil, "A-12"); !errors.Is(err, algorithms.ErrDomainTooSmall) {
+		t.Errorf("Encrypt(%q) error = %v, expected %v", "A-12", err, algorithms.ErrDomainTooSmall)
+	}
+
+	// Keys and options are checked against the radix-2 FF1 underneath when the cipher is created
+	if _, err := algorithms.NewClassPreservingCipher(key[:15]); err == nil {
+		t.Errorf("NewClassPreservingCipher() with a 15-byte key: expected an error")
+	}
+	digits, _ := algorithms.LookupAlphabet("digits")
+	if _, err := algorithms.NewClassPreservingCipher(key, algorithms.WithAlphabet(digits)); !errors.Is(err, algorithms.ErrInvalidRadix) {
+		t.Errorf("NewClassPreservingCipher() with a 10-character alphabet: error = %v, expected %v", err, algorithms.ErrInvalidRadix)
+	}
+}
+
+func TestPANCipher(t *testing.T) {
+	key, _ := hex.DecodeString("637265646974636172646E756D626572")
+	luhnValid := func(pan string) bool {
+		sum, double := 0, false
+		for i := len(pan) - 1; i >= 0; i-- {
+			if pan[i] < '0' || pan[i] > '9' {
+				continue
+			}
+			d := int(pan[i] - '0')
+			if double {
+				if d *= 2; d > 9 {
+					d -= 9
+				}
+			}
+			sum += d
+			double = !double
+		}
+		return sum%10 == 0
+	}
+
+	tests := []struct {
+		name   string
+		format algorithms.PANFormat
+		pan    string
+		kept   string // digits that must stay, as a pattern over the PAN with '*' for encrypted digits
+		valid  bool
+	}{
+		{"Recompute", algorithms.PANFormat{}, "4557534296728436", "****************", true},
+		{"BIN6", algorithms.PANFormat{KeepBIN: 6}, "4111111111111111", "411111**********", true},
+		{"BIN6LastFour", algorithms.PANFormat{KeepBIN: 6, KeepLastFour: true}, "4111 1111 1111 1111", "4111 11** **** 1111", true},
+		{"BIN8LastFour", algorithms.PANFormat{KeepBIN: 8, KeepLastFour: true}, "5555-5555-5555-4444", "5555-5555-****-4444", true},
+		{"Preserve", algorithms.PANFormat{KeepBIN: 6, Luhn: algorithms.LuhnPreserve}, "378282246310005", "378282********5", true},
+		{"Invalid", algorithms.PANFormat{KeepBIN: 6, Luhn: algorithms.LuhnInvalid}, "6011111111111117", "601111**********", false},
+		{"NineteenDigits", algorithms.PANFormat{KeepBIN: 8}, "6011000990139424009", "60110009***********", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, err := algorithms.NewPANCipher(key, tt.format, algorithms.WithSmallDomains())
+			if err != nil {
+				t.Fatalf("NewPANCipher() error: %v", err)
+			}
+			token, err := c.Encrypt([]byte("cards"), tt.pan)
+			if err != nil {
+				t.Fatalf("Encrypt(%q) error: %v", tt.pan, err)
+			}
+			if len(token) != len(tt.pan) {
+				t.Fatalf("Encrypt(%q) = %q, length changed", tt.pan, token)
+			}
+			for i := range tt.kept {
+				if tt.kept[i] != '*' && token[i] != tt.kept[i] {
+					t.Errorf("Encrypt(%q) = %q, position %d not kept", tt.pan, token, i)
+				}
+			}
+			if luhnValid(token) != tt.valid {
+				t.Errorf("Encrypt(%q) = %q, Luhn valid %v, expected %v", tt.pan, token, !tt.valid, tt.valid)
+			}
+			if back, err := c.Decrypt([]byte("cards"), token); err != nil || back != tt.pan {
+				t.Errorf("Decrypt(%q) = %q, %v, expected %q", token, back, err, tt.pan)
+			}
+		})
+	}
+
+	c, _ := algorithms.NewPANCipher(key, algorithms.PANFormat{})
+	if _, err := c.Encrypt(nil, "4111111111111112"); !errors.Is(err, algorithms.ErrCheckDigit) {
+		t.Errorf("Encrypt() of a Luhn-invalid PAN: expected ErrCheckDigit, got %v", err)
+	}
+	if _, err := c.Encrypt(nil, "41111111111"); !errors.Is(err, algorithms.ErrInputLength) {
+		t.Errorf("Encrypt() of an 11-digit PAN: expected ErrInputLength, got %v", err)
+	}
+	if _, err := c.Encrypt(nil, "4111/1111/1111/1111"); !errors.Is(err, algorithms.ErrInvalidCharacter) {
+		t.Errorf("Encrypt() with '/': expected ErrInvalidCharacter, got %v", err)
+	}
+	short, _ := algorithms.NewPANCipher(key, algorithms.PANFormat{KeepBIN: 8, KeepLastFour: true})
+	if _, err := short.Encrypt(nil, "5555555555554444"); !errors.Is(err, algorithms.ErrDomainTooSmall) {
+		t.Errorf("Encrypt() of 4 digits without WithSmallDomains: expected ErrDomainTooSmall, got %v", err)
+	}
+	if _, err := algorithms.NewPANCipher(key, algorithms.PANFormat{KeepBIN: 7}); !errors.Is(err, algorithms.ErrInvalidFormat) {
+		t.Errorf("NewPANCipher() with a 7-digit BIN: expected ErrInvalidFormat, got %v", err)
+	}
+	if _, err := algorithms.NewPANCipher(key, algorithms.PANFormat{KeepLastFour: true, Luhn: algorithms.LuhnInvalid}); !errors.Is(err, algorithms.ErrInvalidFormat) {
+		t.Errorf("NewPANCipher() with KeepLastFour and LuhnInvalid: expected ErrInvalidFormat, got %v", err)
+	}
+}
+
+func TestCheckDigit(t *testing.T) {
+	tests := []struct {
+		name  string
+		check algorithms.CheckDigit
+		valid string
+	}{
+		{"Luhn", algorithms.Luhn{}, "79927398713"},
+		{"IBAN", algorithms.IBAN{}, "GB82WEST12345698765432"},
+		{"IBAN-DE", algorithms.IBAN{}, "DE89370400440532013000"},
+		{"ISBN10", algorithms.ISBN10{}, "0306406152"},
+		{"ISBN10-X", algorithms.ISBN10{}, "080442957X"},
+		{"ISBN13", algorithms.ISBN13{}, "9780306406157"},
+		{"UPC-A", algorithms.EAN{}, "036000291452"},
+		{"EAN-8", algorithms.EAN{}, "96385074"},
+		{"VIN", algorithms.VIN{}, "1M8GDM9AXKP042788"},
+		{"Verhoeff", algorithms.Verhoeff{}, "2363"},
+		{"Damm", algorithms.Damm{}, "5724"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := algorithms.ValidateCheckDigit(tt.check, tt.valid); err != nil {
+				t.Errorf("ValidateCheckDigit(%q) error: %v", tt.valid, err)
+			}
+			// Change one payload character
+			runes := []rune(tt.valid)
+			i := len(runes) - 2
+			if runes[i] == '9' {
+				runes[i] = '8'
+			} else {
+				runes[i]++
+			}
+			if err := algorithms.ValidateCheckDigit(tt.check, string(runes)); !errors.Is(err, algorithms.ErrCheckDigit) {
+				t.Errorf("ValidateCheckDigit(%q): expected ErrCheckDigit, got %v", string(runes), err)
+			}
+		})
+	}
+
+	if err := algorithms.ValidateCheckDigit(algorithms.VIN{}, "11111111\u00e911111111"); !errors.Is(err, algorithms.ErrInvalidCharacter) {
+		t.Errorf("ValidateCheckDigit() of a non-ASCII VIN: expected ErrInvalidCharacter, got %v", err)
+	}
+	if err := algorithms.ValidateCheckDigit(algorithms.VIN{}, "1M8GDM9AXKP04278O"); !errors.Is(err, algorithms.ErrInvalidCharacter) {
+		t.Errorf("ValidateCheckDigit() of a VIN with 'O': expected ErrInvalidCharacter, got %v", err)
+	}
+}
+
+func TestCheckDigitCipher(t *testing.T) {
+	key, _ := hex.DecodeString("2B7E151628AED2A6ABF7158809CF4F3C")
+	digits, _ := algorithms.LookupAlphabet("digits")
+	vinChars, _ := algorithms.NewAlphabet("0123456789ABCDEFGHJKLMNPRSTUVWXYZ")
+
+	tests := []struct {
+		name     string
+		alphabet *algorithms.Alphabet
+		check    algorithms.CheckDigit
+		value    string
+		prefix   string // fixed characters that must stay
+	}{
+		{"Luhn", digits, algorithms.Luhn{}, "4111111111111111", ""},
+		{"ISBN10", digits, algorithms.ISBN10{}, "0306406152", ""},
+		{"ISBN13", digits, algorithms.ISBN13{}, "9780306406157", "978"},
+		{"EAN", digits, algorithms.EAN{}, "036000291452", ""},
+		{"VIN", vinChars, algorithms.VIN{}, "1M8GDM9AXKP042788", ""},
+		{"Verhoeff", digits, algorithms.Verhoeff{}, "12345678902", ""},
+		{"Damm", digits, algorithms.Damm{}, "57240000000", ""},
+		{"IBAN", digits, algorithms.IBAN{}, "DE89370400440532013000", "DE"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			minLen := algorithms.MinLen(tt.alphabet.Radix())
+			c, err := algorithms.NewCheckDigitCipher(key, tt.alphabet, tt.check, minLen, 32, 16)
+			if err != nil {
+				t.Fatalf("NewCheckDigitCipher() error: %v", err)
+			}
+			token, err := c.Encrypt([]byte("ids"), tt.value)
+			if err != nil {
+				t.Fatalf("Encrypt(%q) error: %v", tt.value, err)
+			}
+			if token == tt.value {
+				t.Errorf("Encrypt(%q) returned the input", tt.value)
+			}
+			if err := algorithms.ValidateCheckDigit(tt.check, token); err != nil {
+				t.Errorf("Encrypt(%q) = %q, not valid: %v", tt.value, token, err)
+			}
+			if !strings.HasPrefix(token, tt.prefix) {
+				t.Errorf("Encrypt(%q) = %q, expected the prefix %q to stay", tt.value, token, tt.prefix)
+			}
+			if back, err := c.Decrypt([]byte("ids"), token); err != nil || back != tt.value {
+				t.Errorf("Decrypt(%q) = %q, %v, expected %q", token, back, err, tt.value)
+			}
+		})
+	}
+}
+
+func TestSSNCipher(t *testing.T) {
+	key, _ := hex.DecodeString("EF4359D8D580AA4F7F036D6F04FC6A94")
+	c, err := algorithms.NewSSNCipher(key)
+	if err != nil {
+		t.Fatalf("NewSSNCipher() error: %v", err)
+	}
+
+	for _, ssn := range []string{"078-05-1120", "001-01-0001", "899-99-9999", "665-12-3456", "667-10-0001", "123456789"} {
+		token, err := c.Encrypt([]byte("hr"), ssn)
+		if err != nil {
+			t.Fatalf("Encrypt(%q) error: %v", ssn, err)
+		}
+		if len(token) != len(ssn) || (len(ssn) == 11 && (token[3] != '-' || token[6] != '-')) {
+			t.Errorf("Encrypt(%q) = %q, layout changed", ssn, token)
+		}
+		if !algorithms.ValidSSN(token) {
+			t.Errorf("Encrypt(%q) = %q is not a valid SSN", ssn, token)
+		}
+		if back, err := c.Decrypt([]byte("hr"), token); err != nil || back != ssn {
+			t.Errorf("Decrypt(%q) = %q, %v, expected %q", token, back, err, ssn)
+		}
+	}
+
+	for _, ssn := range []string{"000-12-3456", "666-12-3456", "900-12-3456", "123-00-4567", "123-45-0000"} {
+		if _, err := c.Encrypt(nil, ssn); !errors.Is(err, algorithms.ErrValueOutOfRange) {
+			t.Errorf("Encrypt(%q): expected ErrValueOutOfRange, got %v", ssn, err)
+		}
+	}
+	for _, ssn := range []string{"123-456-789", "12345678", "123 45 6789", "12a-45-6789"} {
+		if _, err := c.Encrypt(nil, ssn); !errors.Is(err, algorithms.ErrFormatMismatch) {
+			t.Errorf("Encrypt(%q): expected ErrFormatMismatch, got %v", ssn, err)
+		}
+	}
+
+	// Exhaustive check of the walk with the real FF1 over a reduced domain: six digits, read as a
+	// three-digit area, a two-digit group and a one-digit serial under the same rules
+	ff1, err := algorithms.NewFF1(key, 10, 6, 6, 16)
+	if err != nil {
+		t.Fatalf("NewFF1() error: %v", err)
+	}
+	step := func(op func([]byte, []uint16) ([]uint16, error)) func(*big.Int) (*big.Int, error) {
+		return func(v *big.Int) (*big.Int, error) {
+			Y, err := op([]byte("hr"), algorithms.BigSTRmRadix(v, 10, 6))
+			if err != nil {
+				return nil, err
+			}
+			return algorithms.BigNUMradix(Y, 10), nil
+		}
+	}
+	valid := func(x *big.Int) bool {
+		v := x.Uint64()
+		area, group, serial := v/1000, v/10%100, v%10
+		return area != 0 && area != 666 && area < 900 && group != 0 && serial != 0
+	}
+
+	const n = 1000000
+	seen := make([]bool, n)
+	reached := 0
+	for x := int64(0); x < n; x++ {
+		X := big.NewInt(x)
+		if !valid(X) {
+			continue
+		}
+		y, err := algorithms.CycleWalkUntil(X, n, step(ff1.Encrypt), valid)
+		if err != nil {
+			t.Fatalf("CycleWalkUntil(%d) error: %v", x, err)
+		}
+		if !valid(y) || seen[y.Int64()] {
+			t.Fatalf("CycleWalkUntil(%d) = %v is invalid or collides with an earlier value", x, y)
+		}
+		seen[y.Int64()] = true
+		reached++
+		if back, err := algorithms.CycleWalkUntil(y, n, step(ff1.Decrypt), valid); err != nil || back.Int64() != x {
+			t.Fatalf("inverse CycleWalkUntil(%v) = %v, %v, expected %d", y, back, err, x)
+		}
+	}
+	// Areas 001-665 and 667-899, groups 01-99, serials 1-9
+	if reached != 898*99*9 {
+		t.Errorf("walk reached %d valid values, expected %d", reached, 898*99*9)
+	}
+}
+
+func TestEmailCipher(t *testing.T) {
+	key, _ := hex.DecodeString("2B7E151628AED2A6ABF7158809CF4F3C")
+
+	// Dots and plus signs of the local part, dots and hyphens of the domain, '*' for everything else
+	shape := func(s string) string {
+		at := strings.LastIndex(s, "@")
+		kept := func(chars string) func(rune) rune {
+			return func(r rune) rune {
+				if strings.ContainsRune(chars, r) {
+					return r
+				}
+				return '*'
+			}
+		}
+		return strings.Map(kept(".+"), s[:at]) + "@" + strings.Map(kept(".-"), s[at+1:])
+	}
+
+	keep, err := algorithms.NewEmailCipher(key, algorithms.EmailFormat{})
+	if err != nil {
+		t.Fatalf("NewEmailCipher() error: %v", err)
+	}
+	encryptDomain, err := algorithms.NewEmailCipher(key, algorithms.EmailFormat{EncryptDomain: true})
+	if err != nil {
+		t.Fatalf("NewEmailCipher() error: %v", err)
+	}
+
+	// The default options take short parts too: "bob", "bo", "x" and "y" below the local minimum
+	// length of 4, "ab" and "co" below the domain minimum length of 4
+	for _, email := range []string{"john.doe+newsletter@example.com", "Jane_Smith-1984@mail.example.co.uk", "bob@example.com", "bo@my-company.io", "x.y@ab.org"} {
+		for name, c := range map[string]*algorithms.EmailCipher{"keep": keep, "encrypt": encryptDomain} {
+			pseudonym, err := c.Encrypt([]byte("crm"), email)
+			if err != nil {
+				t.Fatalf("%s: Encrypt(%q) error: %v", name, email, err)
+			}
+			if shape(pseudonym) != shape(email) {
+				t.Errorf("%s: Encrypt(%q) = %q, layout changed", name, email, pseudonym)
+			}
+			domain := email[strings.LastIndex(email, "@"):]
+			tld := email[strings.LastIndex(email, "."):]
+			if name == "keep" && !strings.HasSuffix(pseudonym, domain) {
+				t.Errorf("%s: Encrypt(%q) = %q, domain changed", name, email, pseudonym)
+			}
+			if name == "encrypt" && (strings.HasSuffix(pseudonym, domain) || !strings.HasSuffix(pseudonym, tld)) {
+				t.Errorf("%s: Encrypt(%q) = %q, expected a new domain with the same TLD", name, email, pseudonym)
+			}
+			if back, err := c.Decrypt([]byte("crm"), pseudonym); err != nil || back != email {
+				t.Errorf("%s: Decrypt(%q) = %q, %v, expected %q", name, pseudonym, back, err, email)
+			}
+		}
+	}
+
+	// The same domain always gets the same pseudonym
+	a, _ := encryptDomain.Encrypt(nil, "alice@example.com")
+	b, _ := encryptDomain.Encrypt(nil, "bob@example.com")
+	if a[strings.Index(a, "@"):] != b[strings.Index(b, "@"):] {
+		t.Errorf("Encrypt() gave example.com two pseudonyms: %q and %q", a, b)
+	}
+
+	// Domains are case-insensitive, the capitals stay in place and decryption is exact
+	for _, email := range []string{"John@Example.COM", "alice@EXAMPLE.com", "x.y@Mail.ExAmple.Org"} {
+		at := strings.LastIndex(email, "@")
+		pseudonym, err := encryptDomain.Encrypt(nil, email)
+		if err != nil {
+			t.Fatalf("Encrypt(%q) error: %v", email, err)
+		}
+		lower, _ := encryptDomain.Encrypt(nil, email[:at]+strings.ToLower(email[at:]))
+		if pseudonym[:at] != lower[:at] || !strings.HasSuffix(pseudonym, email[strings.LastIndex(email, "."):]) {
+			t.Errorf("Encrypt(%q) = %q, expected the local part of %q and the same TLD", email, pseudonym, lower)
+		}
+		for i, r := range email[at:] {
+			if unicode.IsUpper(r) != unicode.IsUpper(rune(pseudonym[at+i])) {
+				t.Errorf("Encrypt(%q) = %q, capitals moved", email, pseudonym)
+				break
+			}
+		}
+		if back, err := encryptDomain.Decrypt(nil, pseudonym); err != nil || back != email {
+			t.Errorf("Decrypt(%q) = %q, %v, expected %q", pseudonym, back, err, email)
+		}
+	}
+	kept, _ := keep.Encrypt(nil, "alice@example.com")
+	if upper, _ := keep.Encrypt(nil, "alice@Example.COM"); upper != strings.TrimSuffix(kept, "example.com")+"Example.COM" {
+		t.Errorf("Encrypt(\"alice@Example.COM\") = %q, expected the local part of %q", upper, kept)
+	}
+
+	for _, email := range []string{"no-at-sign", "@example.com", "john@", ".john@example.com", "jo..hn@example.com"} {
+		if _, err := keep.Encrypt(nil, email); !errors.Is(err, algorithms.ErrFormatMismatch) {
+			t.Errorf("Encrypt(%q): expected ErrFormatMismatch, got %v", email, err)
+		}
+	}
+	if _, err := keep.Encrypt(nil, "jöhn@example.com"); !errors.Is(err, algorithms.ErrInvalidCharacter) {
+		t.Errorf("Encrypt() with 'ö': expected ErrInvalidCharacter, got %v", err)
+	}
+	if _, err := encryptDomain.Encrypt(nil, "john@localhost"); !errors.Is(err, algorithms.ErrFormatMismatch) {
+		t.Errorf("Encrypt() without a TLD: expected ErrFormatMismatch, got %v", err)
+	}
+	upper, _ := algorithms.NewAlphabet("0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZ")
+	if _, err := algorithms.NewEmailCipher(key, algorithms.EmailFormat{DomainAlphabet: upper}); !errors.Is(err, algorithms.ErrInvalidFormat) {
+		t.Errorf("NewEmailCipher() with capitals in the domain alphabet: expected ErrInvalidFormat, got %v", err)
+	}
+}
+
+func TestIPCipher(t *testing.T) {
+	// Sample key and mappings of the Crypto-PAn reference implementation
+	key := []byte{21, 34, 23, 141, 51, 164, 207, 128, 19, 10, 91, 22, 73, 144, 125, 16,
+		216, 152, 143, 131, 121, 121, 101, 39, 98, 87, 76, 45, 42, 132, 34, 2}
+	c, err := algorithms.NewIPCipher(key)
+	if err != nil {
+		t.Fatalf("NewIPCipher() error: %v", err)
+	}
+	vectors := []struct{ in, out string }{
+		{"128.11.68.132", "135.242.180.132"},
+		{"129.118.74.4", "134.136.186.123"},
+		{"130.132.252.244", "133.68.164.234"},
+		{"141.223.7.43", "141.167.8.160"},
+		{"141.233.145.108", "141.129.237.235"},
+	}
+	for _, v := range vectors {
+		got, err := c.Encrypt(netip.MustParseAddr(v.in))
+		if err != nil || got.String() != v.out {
+			t.Errorf("Encrypt(%s) = %v, %v, expected %s", v.in, got, err, v.out)
+		}
+		if back, err := c.Decrypt(netip.MustParseAddr(v.out)); err != nil || back.String() != v.in {
+			t.Errorf("Decrypt(%s) = %v, %v, expected %s", v.out, back, err, v.in)
+		}
+	}
+
+	// Shared prefixes survive, for IPv6 as well
+	commonPrefix := func(a, b netip.Addr) int {
+		x, y := a.AsSlice(), b.AsSlice()
+		for i := range x {
+			if d := x[i] ^ y[i]; d != 0 {
+				n := i * 8
+				for d&0x80 == 0 {
+					d <<= 1
+					n++
+				}
+				return n
+			}
+		}
+		return len(x) * 8
+	}
+	pairs := [][2]string{
+		{"10.1.2.3", "10.1.2.200"},
+		{"192.168.0.1", "192.169.0.1"},
+		{"2001:db8::1", "2001:db8::ffff"},
+		{"2001:db8:aaaa::1", "2001:db8:bbbb::1"},
+		{"fe80::1%eth0", "fe80::2%eth0"},
+	}
+	for _, p := range pairs {
+		a, b := netip.MustParseAddr(p[0]), netip.MustParseAddr(p[1])
+		encA, err := c.Encrypt(a)
+		if err != nil {
+			t.Fatalf("Encrypt(%v) error: %v", a, err)
+		}
+		encB, _ := c.Encrypt(b)
+		if commonPrefix(encA, encB) != commonPrefix(a, b) {
+			t.Errorf("Encrypt(%v) = %v and Encrypt(%v) = %v, common prefix %d, expected %d",
+				a, encA, b, encB, commonPrefix(encA, encB), commonPrefix(a, b))
+		}
+		if encA.Zone() != a.Zone() {
+			t.Errorf("Encrypt(%v) = %v, zone changed", a, encA)
+		}
+		if back, err := c.Decrypt(encA); err != nil || back != a {
+			t.Errorf("Decrypt(%v) = %v, %v, expected %v", encA, back, err, a)
+		}
+	}
+
+	if _, err := algorithms.NewIPCipher(key[:16]); !errors.Is(err, algorithms.ErrInvalidKeySize) {
+		t.Errorf("NewIPCipher() with a 16-byte key: expected ErrInvalidKeySize, got %v", err)
+	}
+	if _, err := c.Encrypt(netip.Addr{}); !errors.Is(err, algorithms.ErrFormatMismatch) {
+		t.Errorf("Encrypt() of the zero Addr: expected ErrFormatMismatch, got %v", err)
+	}
+}
+
+func TestSubnetCipher(t *testing.T) {
+	key, _ := hex.DecodeString("2B7E151628AED2A6ABF7158809CF4F3C")
+
+	for _, tt := range []struct {
+		prefix string
+		addrs  []string
+	}{
+		{"10.20.0.0/16", []string{"10.20.0.0", "10.20.1.2", "10.20.255.255"}},
+		{"172.16.0.0/12", []string{"172.16.0.1", "172.31.200.7"}},
+		{"2001:db8:1:2::/64", []string{"2001:db8:1:2::1", "2001:db8:1:2:dead:beef:0:1"}},
+	} {
+		prefix := netip.MustParsePrefix(tt.prefix)
+		c, err := algorithms.NewSubnetCipher(key, prefix, algorithms.WithSmallDomains())
+		if err != nil {
+			t.Fatalf("NewSubnetCipher(%v) error: %v", prefix, err)
+		}
+		for _, s := range tt.addrs {
+			addr := netip.MustParseAddr(s)
+			enc, err := c.Encrypt([]byte("logs"), addr)
+			if err != nil {
+				t.Fatalf("Encrypt(%v) error: %v", addr, err)
+			}
+			if !prefix.Contains(enc) {
+				t.Errorf("Encrypt(%v) = %v is outside %v", addr, enc, prefix)
+			}
+			if back, err := c.Decrypt([]byte("logs"), enc); err != nil || back != addr {
+				t.Errorf("Decrypt(%v) = %v, %v, expected %v", enc, back, err, addr)
+			}
+		}
+	}
+
+	c, _ := algorithms.NewSubnetCipher(key, netip.MustParsePrefix("10.20.0.0/16"), algorithms.WithSmallDomains())
+	for _, s := range []string{"10.21.0.1", "::ffff:10.20.0.1", "2001:db8::1"} {
+		if _, err := c.Encrypt(nil, netip.MustParseAddr(s)); !errors.Is(err, algorithms.ErrValueOutOfRange) {
+			t.Errorf("Encrypt(%s): expected ErrValueOutOfRange, got %v", s, err)
+		}
+	}
+	if _, err := algorithms.NewSubnetCipher(key, netip.MustParsePrefix("10.20.30.40/32")); !errors.Is(err, algorithms.ErrInvalidDomain) {
+		t.Errorf("NewSubnetCipher() for a /32: expected ErrInvalidDomain, got %v", err)
+	}
+	if _, err := algorithms.NewSubnetCipher(key, netip.MustParsePrefix("10.20.0.0/16")); !errors.Is(err, algorithms.ErrDomainTooSmall) {
+		t.Errorf("NewSubnetCipher() for a /16 without WithSmallDomains: expected ErrDomainTooSmall, got %v", err)
+	}
+	if _, err := algorithms.NewSubnetCipher(key, netip.MustParsePrefix("10.16.0.0/12")); err != nil {
+		t.Errorf("NewSubnetCipher() for a /12 (1,048,576 addresses) error: %v", err)
+	}
+}
+
+func TestDateCipher(t *testing.T) {
+	key, _ := hex.DecodeString("2B7E151628AED2A6ABF7158809CF4F3C")
+	start := time.Date(1900, 1, 1, 0, 0, 0, 0, time.UTC)
+	end := time.Date(2030, 12, 31, 0, 0, 0, 0, time.UTC)
+
+	for _, tt := range []struct {
+		name     string
+		preserve algorithms.DatePreserve
+		values   []string
+	}{
+		{"None", algorithms.DatePreserveNone, []string{"1984-02-29", "1900-01-01", "2030-12-31", "2024-03-10T14:30:00Z", "1999-12-31T23:59:59.123+05:30", "2001-09-09T01:46:40.000000-00:00", "1700000000", "-86400"}},
+		{"Year", algorithms.DatePreserveYear, []string{"1984-02-29", "2030-06-15", "2024-03-10T14:30:00Z", "2023-01-01T00:00:00+01:00", "1700000000"}},
+		{"Month", algorithms.DatePreserveMonth, []string{"1984-02-29", "2024-03-10T14:30:00Z", "2023-12-31T23:59:59-08:00", "1700000000"}},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			c, err := algorithms.NewDateCipher(key, algorithms.DateFormat{Start: start, End: end, Preserve: tt.preserve}, algorithms.WithSmallDomains())
+			if err != nil {
+				t.Fatalf("NewDateCipher() error: %v", err)
+			}
+			for _, s := range tt.values {
+				enc, err := c.EncryptString([]byte("dob"), s)
+				if err != nil {
+					t.Fatalf("EncryptString(%q) error: %v", s, err)
+				}
+				if _, isUnix := new(big.Int).SetString(s, 10); !isUnix && len(enc) != len(s) {
+					t.Errorf("EncryptString(%q) = %q, layout changed", s, enc)
+				}
+				if len(s) > 10 && strings.HasSuffix(s, "Z") != strings.HasSuffix(enc, "Z") || len(s) > 20 && !strings.HasSuffix(s, "Z") && s[len(s)-6:] != enc[len(enc)-6:] {
+					t.Errorf("EncryptString(%q) = %q, offset changed", s, enc)
+				}
+				switch tt.preserve {
+				case algorithms.DatePreserveYear:
+					if enc[:4] != s[:4] && len(s) >= 10 && s[4] == '-' {
+						t.Errorf("EncryptString(%q) = %q, year changed", s, enc)
+					}
+				case algorithms.DatePreserveMonth:
+					if enc[:7] != s[:7] && len(s) >= 10 && s[4] == '-' {
+						t.Errorf("EncryptString(%q) = %q, month changed", s, enc)
+					}
+				}
+				if back, err := c.DecryptString([]byte("dob"), enc); err != nil || back != s {
+					t.Errorf("DecryptString(%q) = %q, %v, expected %q", enc, back, err, s)
+				}
+			}
+		})
+	}
+
+	c, _ := algorithms.NewDateCipher(key, algorithms.DateFormat{Start: start, End: end}, algorithms.WithSmallDomains())
+
+	// Every result stays in the window
+	for i := 0; i < 50; i++ {
+		d := start.AddDate(0, 0, i*967)
+		enc, err := c.EncryptDate(nil, d)
+		if err != nil {
+			t.Fatalf("EncryptDate(%v) error: %v", d, err)
+		}
+		if enc.Before(start) || enc.After(end) {
+			t.Errorf("EncryptDate(%v) = %v is outside the window", d, enc)
+		}
+		if back, err := c.DecryptDate(nil, enc); err != nil || !back.Equal(d) {
+			t.Errorf("DecryptDate(%v) = %v, %v, expected %v", enc, back, err, d)
+		}
+	}
+
+	ts := time.Date(2020, 5, 17, 8, 15, 42, 987654321, time.FixedZone("CEST", 2*3600))
+	enc, err := c.EncryptTimestamp(nil, ts)
+	if err != nil {
+		t.Fatalf("EncryptTimestamp() error: %v", err)
+	}
+	if enc.Nanosecond() != ts.Nanosecond() || enc.Location() != ts.Location() {
+		t.Errorf("EncryptTimestamp(%v) = %v, fraction or location changed", ts, enc)
+	}
+	if back, err := c.DecryptTimestamp(nil, enc); err != nil || !back.Equal(ts) {
+		t.Errorf("DecryptTimestamp(%v) = %v, %v, expected %v", enc, back, err, ts)
+	}
+
+	for _, s := range []string{"1899-12-31", "2031-01-01", "4102444800"} {
+		if _, err := c.EncryptString(nil, s); !errors.Is(err, algorithms.ErrValueOutOfRange) {
+			t.Errorf("EncryptString(%q): expected ErrValueOutOfRange, got %v", s, err)
+		}
+	}
+	for _, s := range []string{"31/12/1999", "1999-13-01", "yesterday"} {
+		if _, err := c.EncryptString(nil, s); !errors.Is(err, algorithms.ErrFormatMismatch) {
+			t.Errorf("EncryptString(%q): expected ErrFormatMismatch, got %v", s, err)
+		}
+	}
+	if _, err := algorithms.NewDateCipher(key, algorithms.DateFormat{Start: end, End: start}); !errors.Is(err, algorithms.ErrInvalidDomain) {
+		t.Errorf("NewDateCipher() with a reversed window: expected ErrInvalidDomain, got %v", err)
+	}
+
+	// Without WithSmallDomains the 47,847 days of the window are rejected up front, and only
+	// a window of 2,738 years or more is accepted
+	for _, preserve := range []algorithms.DatePreserve{algorithms.DatePreserveNone, algorithms.DatePreserveYear, algorithms.DatePreserveMonth} {
+		if _, err := algorithms.NewDateCipher(key, algorithms.DateFormat{Start: start, End: end, Preserve: preserve}); !errors.Is(err, algorithms.ErrDomainTooSmall) {
+			t.Errorf("NewDateCipher() with preservation %d: expected ErrDomainTooSmall, got %v", preserve, err)
+		}
+	}
+	wide, err := algorithms.NewDateCipher(key, algorithms.DateFormat{Start: time.Date(1, 1, 1, 0, 0, 0, 0, time.UTC), End: time.Date(2999, 12, 31, 0, 0, 0, 0, time.UTC)})
+	if err != nil {
+		t.Fatalf("NewDateCipher() over 3000 years error: %v", err)
+	}
+	if enc, err := wide.EncryptString(nil, "1984-02-29"); err != nil {
+		t.Errorf("EncryptString() over 3000 years: %q, %v", enc, err)
+	}
+}
+
+func TestPhoneCipher(t *testing.T) {
+	key, _ := hex.DecodeString("2B7E151628AED2A6ABF7158809CF4F3C")
+
+	// Everything but the digits, to compare separators
+	separators := func(s string) string {
+		return strings.Map(func(r rune) rune {
+			if r >= '0' && r <= '9' {
+				return '*'
+			}
+			return r
+		}, s)
+	}
+
+	for _, tt := range []struct {
+		name   string
+		format algorithms.PhoneFormat
+		number string
+		kept   string // prefix of the number that must stay
+	}{
+		{"E164", algorithms.PhoneFormat{}, "+14155552671", "+1"},
+		{"E164Area", algorithms.PhoneFormat{KeepAreaCode: true}, "+14155552671", "+1415"},
+		{"UK", algorithms.PhoneFormat{KeepAreaCode: true}, "+44 20 7946 0958", "+44 20 "},
+		{"UKTrunk", algorithms.PhoneFormat{KeepAreaCode: true}, "+44 (0)20 7946 0958", "+44 (0)20 "},
+		{"DoubleZero", algorithms.PhoneFormat{}, "0049 30 1234567", "0049 "},
+		{"ThreeDigitCode", algorithms.PhoneFormat{KeepAreaCode: true}, "+353 1 234 5678", "+353 1 "},
+		{"NationalUS", algorithms.PhoneFormat{DefaultCountry: "1", KeepAreaCode: true}, "(415) 555-2671", "(415) "},
+		{"NationalDE", algorithms.PhoneFormat{DefaultCountry: "49", KeepAreaCode: true}, "030/1234567", "030/"},
+		{"NationalFR", algorithms.PhoneFormat{DefaultCountry: "33"}, "01.23.45.67.89", "0"},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			c, err := algorithms.NewPhoneCipher(key, tt.format)
+			if err != nil {
+				t.Fatalf("NewPhoneCipher() error: %v", err)
+			}
+			enc, err := c.Encrypt([]byte("crm"), tt.number)
+			if err != nil {
+				t.Fatalf("Encrypt(%q) error: %v", tt.number, err)
+			}
+			if !strings.HasPrefix(enc, tt.kept) || separators(enc) != separators(tt.number) {
+				t.Errorf("Encrypt(%q) = %q, expected %q kept and the same separators", tt.number, enc, tt.kept)
+			}
+			if enc == tt.number {
+				t.Errorf("Encrypt(%q) returned the input", tt.number)
+			}
+			if back, err := c.Decrypt([]byte("crm"), enc); err != nil || back != tt.number {
+				t.Errorf("Decrypt(%q) = %q, %v, expected %q", enc, back, err, tt.number)
+			}
+		})
+	}
+
+	// Encrypted area codes never start with the trunk digit, under any tweak
+	international, _ := algorithms.NewPhoneCipher(key, algorithms.PhoneFormat{})
+	national, _ := algorithms.NewPhoneCipher(key, algorithms.PhoneFormat{DefaultCountry: "44"})
+	for i := 0; i < 500; i++ {
+		tweak := []byte(fmt.Sprintf("tweak %d", i))
+		for number, c := range map[string]*algorithms.PhoneCipher{"+44 20 7946 0958": international, "020 7946 0958": national} {
+			enc, err := c.Encrypt(tweak, number)
+			if err != nil {
+				t.Fatalf("Encrypt(%q) error: %v", number, err)
+			}
+			if back, err := c.Decrypt(tweak, enc); err != nil || back != number {
+				t.Fatalf("tweak %d: Decrypt(%q) = %q, %v, expected %q", i, enc, back, err, number)
+			}
+		}
+	}
+
+	c, _ := algorithms.NewPhoneCipher(key, algorithms.PhoneFormat{KeepAreaCode: true})
+	for _, number := range []string{"415 555 2671", "+44 2079460958", "+1 415"} {
+		if _, err := c.Encrypt(nil, number); !errors.Is(err, algorithms.ErrFormatMismatch) && !errors.Is(err, algorithms.ErrInputLength) {
+			t.Errorf("Encrypt(%q): expected ErrFormatMismatch or ErrInputLength, got %v", number, err)
+		}
+	}
+	if _, err := c.Encrypt(nil, "+1 415 555 CALL"); !errors.Is(err, algorithms.ErrInvalidCharacter) {
+		t.Errorf("Encrypt() with letters: expected ErrInvalidCharacter, got %v", err)
+	}
+	if _, err := c.Encrypt(nil, "+1 415 555 2671 1234 5"); !errors.Is(err, algorithms.ErrInputLength) {
+		t.Errorf("Encrypt() of 16 digits: expected ErrInputLength, got %v", err)
+	}
+	if _, err := c.Encrypt(nil, "+44 20 79460"); !errors.Is(err, algorithms.ErrDomainTooSmall) {
+		t.Errorf("Encrypt() of 5 digits without WithSmallDomains: expected ErrDomainTooSmall, got %v", err)
+	}
+	if _, err := algorithms.NewPhoneCipher(key, algorithms.PhoneFormat{DefaultCountry: "4"}); !errors.Is(err, algorithms.ErrInvalidFormat) {
+		t.Errorf("NewPhoneCipher() with country code 4: expected ErrInvalidFormat, got %v", err)
+	}
+}
+
+func TestIBANCipher(t *testing.T) {
+	key, _ := hex.DecodeString("2B7E151628AED2A6ABF7158809CF4F3C")
+	ibans := []string{
+		"DE89370400440532013000",
+		"GB82WEST12345698765432",
+		"FR1420041010050500013M02606",
+		"NL91ABNA0417164300",
+		"CH9300762011623852957",
+		"IT60X0542811101000000123456",
+		"NO9386011117947",
+		"MU17BOMM0101101030300200000MUR",
+		"DE89 3704 0044 0532 0130 00",
+	}
+
+	for _, keepBank := range []bool{false, true} {
+		c, err := algorithms.NewIBANCipher(key, algorithms.IBANFormat{KeepBank: keepBank})
+		if err != nil {
+			t.Fatalf("NewIBANCipher() error: %v", err)
+		}
+		for _, iban := range ibans {
+			enc, err := c.Encrypt([]byte("bank"), iban)
+			if err != nil {
+				t.Fatalf("Encrypt(%q) error: %v", iban, err)
+			}
+			electronic := strings.ReplaceAll(enc, " ", "")
+			if err := algorithms.ValidateIBAN(electronic); err != nil {
+				t.Errorf("Encrypt(%q) = %q is not a valid IBAN: %v", iban, enc, err)
+			}
+			if enc[:2] != iban[:2] || strings.Count(enc, " ") != strings.Count(iban, " ") {
+				t.Errorf("Encrypt(%q) = %q, country or layout changed", iban, enc)
+			}
+			if keepBank && iban == ibans[0] && electronic[4:12] != iban[4:12] {
+				t.Errorf("Encrypt(%q) = %q, bank code changed", iban, enc)
+			}
+			if back, err := c.Decrypt([]byte("bank"), enc); err != nil || back != iban {
+				t.Errorf("Decrypt(%q) = %q, %v, expected %q", enc, back, err, iban)
+			}
+		}
+	}
+
+	if n, ok := algorithms.IBANLength("DE"); !ok || n != 22 {
+		t.Errorf("IBANLength(DE) = %d, %v, expected 22, true", n, ok)
+	}
+	// Examples from the IBAN registry
+	for _, iban := range []string{
+		"BI4210000100010000332045181", "BY13NBRB3600900000002Z00AB00", "DJ2100010000000154000100186",
+		"FK88SC123456789012", "HN88CABF00000000000250005469", "IQ98NBIQ850123456789012",
+		"LC55HEMM000100010012001200023015", "LY83002048000020100120361", "MN121234123456789123",
+		"NI45BAPR00000013000003558124", "OM810180000001299123456", "RU0304452522540817810538091310419",
+		"SC18SSCB11010000000000001497USD", "SD2129010501234001", "SO211000001001000100141",
+		"ST68000100010051845310112", "SV62CENR00000000000000700025", "TL380080012345678910157",
+		"VA59001123000012345678", "YE15CBYE0001018861234567891234",
+	} {
+		if err := algorithms.ValidateIBAN(iban); err != nil {
+			t.Errorf("ValidateIBAN(%q) error: %v", iban, err)
+		}
+	}
+	c, _ := algorithms.NewIBANCipher(key, algorithms.IBANFormat{})
+	if _, err := c.Encrypt(nil, "DE88370400440532013000"); !errors.Is(err, algorithms.ErrCheckDigit) {
+		t.Errorf("Encrypt() with wrong check digits: expected ErrCheckDigit, got %v", err)
+	}
+	for _, iban := range []string{"US12345678901234", "DE8937040044053201300", "GB82WEST1234569876543X", "gb82west12345698765432"} {
+		if _, err := c.Encrypt(nil, iban); !errors.Is(err, algorithms.ErrFormatMismatch) {
+			t.Errorf("Encrypt(%q): expected ErrFormatMismatch, got %v", iban, err)
+		}
+	}
+}
+
+func TestUUIDCipher(t *testing.T) {
+	key, _ := hex.DecodeString("2B7E151628AED2A6ABF7158809CF4F3C")
+	c, err := algorithms.NewUUIDCipher(key)
+	if err != nil {
+		t.Fatalf("NewUUIDCipher() error: %v", err)
+	}
+
+	for _, tt := range []struct {
+		uuid        string
+		variantMask byte // top bits of octet 8 that hold the variant
+	}{
+		{"f47ac10b-58cc-4372-a567-0e02b2c3d479", 0xc0},
+		{"F47AC10B-58CC-4372-A567-0E02B2C3D479", 0xc0},
+		{"{f47ac10b-58cc-4372-a567-0e02b2c3d479}", 0xc0},
+		{"01890a5d-ac96-774b-bcce-b302099a8057", 0xc0},
+		{"00000000-0000-0000-0000-000000000000", 0x80},
+		{"{00020906-0000-0000-C000-000000000046}", 0xe0},
+	} {
+		enc, err := c.Encrypt([]byte("fk"), tt.uuid)
+		if err != nil {
+			t.Fatalf("Encrypt(%q) error: %v", tt.uuid, err)
+		}
+		if enc == tt.uuid || len(enc) != len(tt.uuid) {
+			t.Errorf("Encrypt(%q) = %q, expected a different UUID of the same form", tt.uuid, enc)
+		}
+		wrongCase := "ABCDEF"
+		if strings.ContainsAny(tt.uuid, "ABCDEF") {
+			wrongCase = "abcdef"
+		}
+		if (enc[0] == '{') != (tt.uuid[0] == '{') || strings.ContainsAny(enc, wrongCase) {
+			t.Errorf("Encrypt(%q) = %q, form changed", tt.uuid, enc)
+		}
+
+		in, _ := hex.DecodeString(strings.NewReplacer("-", "", "{", "", "}", "").Replace(tt.uuid))
+		out, _ := hex.DecodeString(strings.NewReplacer("-", "", "{", "", "}", "").Replace(enc))
+		if in[6]>>4 != out[6]>>4 {
+			t.Errorf("Encrypt(%q) = %q, version changed", tt.uuid, enc)
+		}
+		if in[8]&tt.variantMask != out[8]&tt.variantMask {
+			t.Errorf("Encrypt(%q) = %q, variant changed", tt.uuid, enc)
+		}
+
+		if back, err := c.Decrypt([]byte("fk"), enc); err != nil || back != tt.uuid {
+			t.Errorf("Decrypt(%q) = %q, %v, expected %q", enc, back, err, tt.uuid)
+		}
+	}
+
+	u := [16]byte{0x55, 0x0e, 0x84, 0x00, 0xe2, 0x9b, 0x41, 0xd4, 0xa7, 0x16, 0x44, 0x66, 0x55, 0x44, 0x00, 0x00}
+	enc, err := c.EncryptUUID(nil, u)
+	if err != nil {
+		t.Fatalf("EncryptUUID() error: %v", err)
+	}
+	if back, err := c.DecryptUUID(nil, enc); err != nil || back != u {
+		t.Errorf("DecryptUUID(%x) = %x, %v, expected %x", enc, back, err, u)
+	}
+
+	for _, s := range []string{"f47ac10b58cc4372a5670e02b2c3d479", "{f47ac10b-58cc-4372-a567-0e02b2c3d479", "F47AC10B-58cc-4372-a567-0e02b2c3d479", "g47ac10b-58cc-4372-a567-0e02b2c3d479"} {
+		if _, err := c.Encrypt(nil, s); !errors.Is(err, algorithms.ErrFormatMismatch) {
+			t.Errorf("Encrypt(%q): expected ErrFormatMismatch, got %v", s, err)
+		}
+	}
+}